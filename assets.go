@@ -0,0 +1,33 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// webFiles contient les pages et fichiers statiques : le binaire ne dépend pas
+// du répertoire courant (ASSETS_DIR permet de les servir depuis le disque en développement)
+//
+//go:embed index.html templates web/static web/templates
+var webFiles embed.FS
+
+// fixtureFiles contient les fixtures hors-ligne (géocodeur, extraits) : le
+// chemin relatif par défaut fonctionne quel que soit le répertoire courant
+//
+//go:embed fixtures
+var fixtureFiles embed.FS
+
+// readFixture lit un fichier de fixtures sur le disque ; un chemin relatif
+// absent du répertoire courant est cherché dans les fixtures embarquées
+func readFixture(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !filepath.IsAbs(path) {
+		if embedded, embeddedErr := fixtureFiles.ReadFile(filepath.ToSlash(filepath.Clean(path))); embeddedErr == nil {
+			return embedded, nil
+		}
+	}
+	return data, err
+}
@@ -1,47 +1,106 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 
 	"groupiepersso/internal/core"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
+	"groupiepersso/internal/database"
+	"groupiepersso/internal/logging"
 )
 
-// Favorite représente un artiste favori
-type Favorite struct {
-	ID          int    `gorm:"primaryKey"`
-	ArtistID    int    `gorm:"uniqueIndex"`
-	ArtistName  string
-	ArtistImage string
-	CreatedAt   int64
-}
+const usage = `Usage : migrate <commande>
+
+Commandes :
+  up            applique les migrations en attente
+  down [n]      annule les n dernières migrations (1 par défaut)
+  status        affiche l'état de chaque migration
+  create <nom>  crée une paire de fichiers vides dans ` + database.MigrationsDir
 
 func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
 	cfg := core.LoadConfig()
+	logging.Setup(cfg.LogLevel, cfg.Environment)
 
-	// Parser DATABASE_URL si présent
-	if err := cfg.ParseDatabaseURL(); err != nil {
-		log.Fatalf("❌ Erreur parsing DATABASE_URL: %v", err)
+	// create ne touche pas à la base de données
+	if cmd == "create" {
+		if len(args) == 0 {
+			fatal("Nom de migration requis : migrate create <nom>", nil)
+		}
+		up, down, err := database.CreateMigration(database.MigrationsDir, strings.Join(args, "_"))
+		if err != nil {
+			fatal("Erreur création migration", err)
+		}
+		slog.Info("Migration créée", "up", up, "down", down)
+		return
 	}
 
-	dsn := cfg.GetDBConnectionString()
-	log.Printf("📦 Connexion à PostgreSQL: %s", dsn)
-
-	// Initialiser GORM
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := database.Open(cfg)
 	if err != nil {
-		log.Fatalf("❌ Erreur connexion PostgreSQL: %v", err)
+		fatal("Erreur connexion PostgreSQL", err)
 	}
+	defer db.Close()
 
-	// AutoMigrate crée la table si elle n'existe pas
-	if err := db.AutoMigrate(&Favorite{}); err != nil {
-		log.Fatalf("❌ Erreur migration: %v", err)
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fatal("Erreur lecture des migrations", err)
 	}
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			fatal("Erreur migration", err)
+		}
+		slog.Info("Migrations appliquées", "count", len(applied))
 
-	log.Println("✅ Base de données initialisée avec succès!")
-	log.Println("✅ Table 'favorites' créée/vérifiée")
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				fatal("Nombre de migrations invalide : "+args[0], nil)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			fatal("Erreur annulation", err)
+		}
+		slog.Info("Migrations annulées", "count", len(reverted))
 
-	os.Exit(0)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fatal("Erreur lecture du statut", err)
+		}
+		for _, s := range status {
+			state := "en attente"
+			if s.AppliedAt != nil {
+				state = "appliquée le " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// fatal journalise l'erreur et termine la commande avec le code 1
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, "err", err)
+	} else {
+		slog.Error(msg)
+	}
+	os.Exit(1)
 }
@@ -1,157 +1,161 @@
 package main
 
 import (
-	"database/sql"
-	"html/template"
-	"log"
 	"net/http"
 	"strconv"
 
-	"groupiepersso/internal/database"
+	"groupiepersso/internal/assets"
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/handlers"
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/models"
 )
 
-func addFavorite(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if database.DB == nil {
-		http.Error(w, "Base de données indisponible", http.StatusServiceUnavailable)
-		return
-	}
-
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Formulaire invalide", http.StatusBadRequest)
-		return
-	}
-
-	artistID, err := strconv.Atoi(r.FormValue("artist_id"))
-	if err != nil {
-		http.Error(w, "artist_id invalide", http.StatusBadRequest)
-		return
-	}
+func addFavorite(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-	artistName := r.FormValue("artist_name")
-	if artistName == "" {
-		http.Error(w, "artist_name requis", http.StatusBadRequest)
-		return
-	}
+		r.Body = http.MaxBytesReader(w, r.Body, handlers.MaxFavoriteBody)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Formulaire invalide", http.StatusBadRequest)
+			return
+		}
 
-	// Utiliser sql.DB directement (INSERT ... ON CONFLICT DO NOTHING)
-	_, err = database.DB.Exec(`
-		INSERT INTO favorites (artist_id, artist_name, artist_image)
-		VALUES ($1, $2, '')
-		ON CONFLICT (artist_id) DO NOTHING
-	`, artistID, artistName)
+		artistID, err := strconv.Atoi(r.FormValue("artist_id"))
+		if err != nil {
+			http.Error(w, "artist_id invalide", http.StatusBadRequest)
+			return
+		}
 
-	if err != nil {
-		log.Printf("❌ Erreur insertion favori (SQL): %v", err)
-		http.Error(w, "Erreur insertion favori", http.StatusInternalServerError)
-		return
-	}
+		artistName := r.FormValue("artist_name")
+		if artistName == "" {
+			http.Error(w, "artist_name requis", http.StatusBadRequest)
+			return
+		}
 
-	http.Redirect(w, r, "/favorites", http.StatusSeeOther)
-}
+		fav := models.Favorite{ArtistID: artistID, ArtistName: artistName}
+		if !handlers.ValidFavorite(fav) {
+			http.Error(w, "artist_name trop long", http.StatusBadRequest)
+			return
+		}
 
-func favoritesPage(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+		// Visiteur anonyme : favoris conservés dans un cookie
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			if _, ok := handlers.AddAnonymousFavorite(w, r, fav); !ok {
+				http.Error(w, "Favori trop volumineux", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Redirect(w, r, "/favorites", http.StatusSeeOther)
+			return
+		}
 
-	if database.DB == nil {
-		http.Error(w, "Base de données indisponible", http.StatusServiceUnavailable)
-		return
-	}
+		if _, _, err := store.Add(r.Context(), user.ID, fav); err != nil {
+			logging.FromContext(r.Context()).Error("Erreur insertion favori", "err", err)
+			http.Error(w, "Erreur insertion favori", http.StatusInternalServerError)
+			return
+		}
 
-	rows, err := database.DB.Query(`
-		SELECT id, artist_id, artist_name, artist_image, created_at
-		FROM favorites
-		ORDER BY created_at DESC
-	`)
-	if err != nil {
-		log.Printf("❌ Erreur lecture favoris: %v", err)
-		http.Error(w, "Erreur lecture favoris", http.StatusInternalServerError)
-		return
+		http.Redirect(w, r, "/favorites", http.StatusSeeOther)
 	}
-	defer rows.Close()
+}
 
-	var favorites []map[string]interface{}
-	for rows.Next() {
-		var id int
-		var artistID int
-		var artistName string
-		var artistImage string
-		var createdAt sql.NullString
+func favoritesPage(store favorites.Store, pages *assets.Assets) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-		if err := rows.Scan(&id, &artistID, &artistName, &artistImage, &createdAt); err != nil {
-			log.Printf("❌ Erreur scan: %v", err)
-			continue
+		// Visiteur anonyme : favoris du cookie
+		user := auth.UserFromContext(r.Context())
+		favs := handlers.AnonymousFavorites(r)
+		if user != nil {
+			var err error
+			favs, err = store.List(r.Context(), user.ID)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("Erreur lecture favoris", "err", err)
+				http.Error(w, "Erreur lecture favoris", http.StatusInternalServerError)
+				return
+			}
 		}
 
-		createdAtStr := ""
-		if createdAt.Valid {
-			createdAtStr = createdAt.String
+		var rows []map[string]interface{}
+		for _, fav := range favs {
+			rows = append(rows, map[string]interface{}{
+				"ID":          fav.ID,
+				"ArtistID":    fav.ArtistID,
+				"ArtistName":  fav.ArtistName,
+				"ArtistImage": fav.ArtistImage,
+				"CreatedAt":   fav.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
 		}
 
-		favorites = append(favorites, map[string]interface{}{
-			"ID":          id,
-			"ArtistID":    artistID,
-			"ArtistName":  artistName,
-			"ArtistImage": artistImage,
-			"CreatedAt":   createdAtStr,
-		})
+		renderFavorites(w, r, pages, rows)
 	}
+}
 
-	tpl, err := template.ParseFiles("templates/favorites.html")
+// renderFavorites affiche la page des favoris
+func renderFavorites(w http.ResponseWriter, r *http.Request, pages *assets.Assets, favorites []map[string]interface{}) {
+	tpl, err := pages.Template("templates/favorites.html")
 	if err != nil {
-		log.Printf("❌ Erreur parse template: %v", err)
+		logging.FromContext(r.Context()).Error("Erreur parse template", "err", err)
 		http.Error(w, "Erreur template favorites", http.StatusInternalServerError)
 		return
 	}
 
 	if err := tpl.Execute(w, favorites); err != nil {
-		log.Printf("❌ Erreur rendu template: %v", err)
+		logging.FromContext(r.Context()).Error("Erreur rendu template", "err", err)
 		http.Error(w, "Erreur rendu template", http.StatusInternalServerError)
 		return
 	}
 }
 
-func removeFavorite(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+func removeFavorite(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-	if database.DB == nil {
-		http.Error(w, "Base de données indisponible", http.StatusServiceUnavailable)
-		return
-	}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Formulaire invalide", http.StatusBadRequest)
+			return
+		}
 
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Formulaire invalide", http.StatusBadRequest)
-		return
-	}
+		// Visiteur anonyme : suppression dans le cookie par artist_id
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			artistID, err := strconv.Atoi(r.FormValue("artist_id"))
+			if err != nil {
+				http.Error(w, "artist_id invalide", http.StatusBadRequest)
+				return
+			}
+			handlers.RemoveAnonymousFavorite(w, r, artistID)
+			http.Redirect(w, r, "/favorites", http.StatusSeeOther)
+			return
+		}
 
-	id, err := strconv.Atoi(r.FormValue("id"))
-	if err != nil {
-		http.Error(w, "id invalide", http.StatusBadRequest)
-		return
-	}
+		id, err := strconv.Atoi(r.FormValue("id"))
+		if err != nil {
+			http.Error(w, "id invalide", http.StatusBadRequest)
+			return
+		}
 
-	result, err := database.DB.Exec(`DELETE FROM favorites WHERE id = $1`, id)
-	if err != nil {
-		log.Printf("❌ Erreur suppression favori: %v", err)
-		http.Error(w, "Erreur suppression favori", http.StatusInternalServerError)
-		return
-	}
+		removed, err := store.RemoveByID(r.Context(), user.ID, id)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Erreur suppression favori", "err", err)
+			http.Error(w, "Erreur suppression favori", http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			logging.FromContext(r.Context()).Warn("Aucune ligne supprimée", "id", id)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil || rowsAffected == 0 {
-		log.Printf("⚠️ Aucune ligne supprimée (id=%d)", id)
+		http.Redirect(w, r, "/favorites", http.StatusSeeOther)
 	}
-
-	http.Redirect(w, r, "/favorites", http.StatusSeeOther)
 }
-
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/handlers"
+	"groupiepersso/internal/models"
+)
+
+// postForm envoie un formulaire à h, authentifié si userID > 0
+func postForm(h http.HandlerFunc, form url.Values, userID int, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if userID > 0 {
+		r = r.WithContext(auth.WithUser(r.Context(), &models.User{ID: userID}))
+	}
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	h(w, r)
+	return w
+}
+
+func TestFavoriteForms(t *testing.T) {
+	ctx := context.Background()
+	store := favorites.NewMemoryStore()
+	add := addFavorite(store)
+	remove := removeFavorite(store)
+
+	queen := url.Values{"artist_id": {"1"}, "artist_name": {"Queen"}}
+	for i := 0; i < 2; i++ {
+		if w := postForm(add, queen, 1); w.Code != http.StatusSeeOther {
+			t.Fatalf("ajout %d: statut %d, attendu 303", i+1, w.Code)
+		}
+	}
+	favs, _ := store.List(ctx, 1)
+	if len(favs) != 1 {
+		t.Fatalf("%d favoris après l'ajout en double, attendu 1", len(favs))
+	}
+	if other, _ := store.List(ctx, 2); len(other) != 0 {
+		t.Fatalf("favoris visibles par un autre utilisateur: %v", other)
+	}
+
+	for _, tt := range []struct {
+		name string
+		form url.Values
+	}{
+		{"artist_id invalide", url.Values{"artist_id": {"x"}, "artist_name": {"Queen"}}},
+		{"artist_name manquant", url.Values{"artist_id": {"2"}}},
+		{"artist_name trop long", url.Values{"artist_id": {"2"}, "artist_name": {strings.Repeat("a", 256)}}},
+	} {
+		if w := postForm(add, tt.form, 1); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: statut %d, attendu 400", tt.name, w.Code)
+		}
+	}
+
+	// Le retrait d'un autre utilisateur n'a pas d'effet
+	id := url.Values{"id": {strconv.Itoa(favs[0].ID)}}
+	postForm(remove, id, 2)
+	if ok, _ := store.Exists(ctx, 1, 1); !ok {
+		t.Fatal("favori retiré par un autre utilisateur")
+	}
+	if w := postForm(remove, id, 1); w.Code != http.StatusSeeOther {
+		t.Fatalf("retrait: statut %d, attendu 303", w.Code)
+	}
+	if ok, _ := store.Exists(ctx, 1, 1); ok {
+		t.Error("favori toujours présent après retrait")
+	}
+}
+
+func TestFavoriteFormsAnonymous(t *testing.T) {
+	store := favorites.NewMemoryStore()
+
+	w := postForm(addFavorite(store), url.Values{"artist_id": {"1"}, "artist_name": {"Queen"}}, 0)
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusSeeOther || len(cookies) != 1 || cookies[0].Name != handlers.AnonymousFavoritesCookie {
+		t.Fatalf("ajout anonyme: statut %d, cookies %v", w.Code, cookies)
+	}
+	if st, _ := store.Stats(context.Background()); st.Favorites != 0 {
+		t.Fatalf("%d favoris dans le store, attendu 0", st.Favorites)
+	}
+
+	// Le retrait anonyme se fait par artist_id et efface le cookie devenu vide
+	w = postForm(removeFavorite(store), url.Values{"artist_id": {"1"}}, 0, cookies[0])
+	cookies = w.Result().Cookies()
+	if w.Code != http.StatusSeeOther || len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("retrait anonyme: statut %d, cookies %v", w.Code, cookies)
+	}
+}
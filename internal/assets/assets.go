@@ -0,0 +1,199 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StaticDir est le dossier des fichiers statiques, servis sous /static/
+const StaticDir = "web/static"
+
+// immutable est le Cache-Control des fichiers dont le nom contient l'empreinte
+const immutable = "public, max-age=31536000, immutable"
+
+// staticRef reconnaît une référence à un fichier statique dans du HTML, du CSS
+// ou du JS, avec l'ancien suffixe de version manuel (?v=20260123) éventuel
+var staticRef = regexp.MustCompile(`/static/([A-Za-z0-9_./-]+)(\?v=[A-Za-z0-9]*)?`)
+
+// file est un fichier statique prêt à être servi
+type file struct {
+	data []byte
+	etag string
+}
+
+// Assets sert les pages et fichiers statiques depuis un fs.FS (embarqué dans
+// le binaire en production). Les fichiers statiques sont publiés sous un nom
+// contenant l'empreinte de leur contenu (css/style.3f2a9c1b.css), ce qui rend
+// sûr le cache d'un an : toute modification change l'URL.
+type Assets struct {
+	fsys fs.FS
+	dev  bool
+
+	hashed map[string]string // nom d'origine -> nom publié
+	files  map[string]file   // nom publié (ou d'origine) -> contenu
+}
+
+// New prépare les fichiers de fsys. En mode dev, les fichiers sont relus à
+// chaque requête, sans empreinte ni cache.
+func New(fsys fs.FS, dev bool) (*Assets, error) {
+	a := &Assets{fsys: fsys, dev: dev, hashed: make(map[string]string), files: make(map[string]file)}
+	if dev {
+		return a, nil
+	}
+
+	var names []string
+	err := fs.WalkDir(fsys, StaticDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		names = append(names, strings.TrimPrefix(p, StaticDir+"/"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Les feuilles de style et scripts référencent les images : les images
+	// sont publiées d'abord pour que l'empreinte des CSS/JS tienne compte
+	// des URL réécrites
+	var texts []string
+	for _, name := range names {
+		if isText(name) {
+			texts = append(texts, name)
+			continue
+		}
+		if err := a.publish(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range texts {
+		if err := a.publish(name, a.rewrite); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// publish lit un fichier statique, le transforme éventuellement et
+// l'enregistre sous son nom publié et son nom d'origine
+func (a *Assets) publish(name string, transform func([]byte) []byte) error {
+	data, err := fs.ReadFile(a.fsys, path.Join(StaticDir, name))
+	if err != nil {
+		return err
+	}
+	if transform != nil {
+		data = transform(data)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:10]
+	ext := path.Ext(name)
+	public := strings.TrimSuffix(name, ext) + "." + hash + ext
+
+	f := file{data: data, etag: `"` + hash + `"`}
+	a.hashed[name] = public
+	a.files[public] = f
+	a.files[name] = f
+	return nil
+}
+
+// rewrite remplace les références /static/... connues par leur nom publié
+func (a *Assets) rewrite(data []byte) []byte {
+	return staticRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		m := staticRef.FindSubmatch(ref)
+		if public, ok := a.hashed[string(m[1])]; ok {
+			return []byte("/static/" + public)
+		}
+		return ref
+	})
+}
+
+// Path retourne l'URL publiée d'un fichier statique ("css/style.css")
+func (a *Assets) Path(name string) string {
+	if public, ok := a.hashed[name]; ok {
+		return "/static/" + public
+	}
+	return "/static/" + name
+}
+
+// Static sert /static/ : cache d'un an pour les noms avec empreinte,
+// revalidation systématique pour les autres (anciens liens, mode dev)
+func (a *Assets) Static() http.Handler {
+	return http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean(r.URL.Path)
+		if name == "." || strings.HasPrefix(name, "../") {
+			http.NotFound(w, r)
+			return
+		}
+
+		if a.dev {
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFileFS(w, r, a.fsys, path.Join(StaticDir, name))
+			return
+		}
+
+		f, ok := a.files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if _, original := a.hashed[name]; original {
+			w.Header().Set("Cache-Control", "no-cache")
+		} else {
+			w.Header().Set("Cache-Control", immutable)
+		}
+		w.Header().Set("ETag", f.etag)
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(f.data))
+	}))
+}
+
+// Page retourne le contenu d'une page HTML avec les URL statiques réécrites
+func (a *Assets) Page(name string) ([]byte, error) {
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return a.rewrite(data), nil
+}
+
+// ServePage sert une page HTML (toujours revalidée par le navigateur)
+func (a *Assets) ServePage(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := a.Page(name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		sum := sha256.Sum256(data)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])[:10]+`"`)
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+	}
+}
+
+// Template analyse une page comme template html/template
+func (a *Assets) Template(name string) (*template.Template, error) {
+	data, err := a.Page(name)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(path.Base(name)).Parse(string(data))
+}
+
+// isText indique si un fichier statique peut référencer d'autres fichiers
+func isText(name string) bool {
+	switch path.Ext(name) {
+	case ".css", ".js":
+		return true
+	}
+	return false
+}
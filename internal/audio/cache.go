@@ -0,0 +1,307 @@
+package audio
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTooLarge est retourné quand un extrait dépasse la taille autorisée
+var ErrTooLarge = errors.New("audio: extrait trop volumineux")
+
+// Entry décrit un extrait présent dans le cache disque
+type Entry struct {
+	URL          string    `json:"url"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"` // dernière validation auprès du CDN
+	Size         int64     `json:"size"`
+
+	key string
+}
+
+// Cache garde les extraits audio sur disque, dans la limite de maxBytes :
+// les extraits les moins récemment servis sont supprimés en premier (LRU).
+// Chaque extrait occupe deux fichiers : <clé>.audio et <clé>.json.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List               // front = plus récemment utilisé
+	entries map[string]*list.Element // clé -> élément contenant *Entry
+}
+
+// NewCache ouvre (ou crée) le cache du dossier dir et recharge son index
+func NewCache(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &Cache{dir: dir, maxBytes: maxBytes, lru: list.New(), entries: make(map[string]*list.Element)}
+
+	// Écritures interrompues par un arrêt du serveur
+	if tmps, err := filepath.Glob(filepath.Join(dir, "*.tmp")); err == nil {
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}
+
+	metas, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	type loaded struct {
+		entry *Entry
+		used  time.Time
+	}
+	var found []loaded
+	for _, metaPath := range metas {
+		key := strings.TrimSuffix(filepath.Base(metaPath), ".json")
+		e, used, err := c.load(key)
+		if err != nil {
+			// Fichiers orphelins ou incomplets (arrêt pendant une écriture)
+			c.removeFiles(key)
+			continue
+		}
+		found = append(found, loaded{e, used})
+	}
+
+	// Le dernier accès est la date de modification du fichier audio
+	sort.Slice(found, func(i, j int) bool { return found[i].used.After(found[j].used) })
+	for _, f := range found {
+		c.entries[f.entry.key] = c.lru.PushBack(f.entry)
+		c.size += f.entry.Size
+	}
+	c.mu.Lock()
+	c.evict("")
+	c.mu.Unlock()
+	if len(found) > 0 {
+		slog.Info("Cache audio chargé", "entries", c.lru.Len(), "bytes", c.size, "dir", dir)
+	}
+	return c, nil
+}
+
+// load relit les métadonnées d'un extrait et vérifie son fichier audio
+func (c *Cache) load(key string) (*Entry, time.Time, error) {
+	data, err := os.ReadFile(c.metaPath(key))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, time.Time{}, err
+	}
+	fi, err := os.Stat(c.dataPath(key))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if fi.Size() != e.Size {
+		return nil, time.Time{}, errors.New("taille incohérente")
+	}
+	e.key = key
+	return &e, fi.ModTime(), nil
+}
+
+// Get retourne l'extrait de url s'il est en cache et le marque comme utilisé
+func (c *Cache) Get(url string) (*Entry, bool) {
+	key := cacheKey(url)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	now := time.Now()
+	os.Chtimes(c.dataPath(key), now, now)
+	e := *el.Value.(*Entry)
+	return &e, true
+}
+
+// Open ouvre le fichier audio d'un extrait
+func (c *Cache) Open(e *Entry) (*os.File, error) {
+	return os.Open(c.dataPath(e.key))
+}
+
+// Put enregistre le corps d'un extrait (au plus limit octets) et retourne
+// l'entrée créée
+func (c *Cache) Put(e Entry, body io.Reader, limit int64) (*Entry, error) {
+	w, err := c.Create(e, limit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, io.LimitReader(body, w.limit+1)); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return w.Commit()
+}
+
+// Writer écrit un extrait au fil de son téléchargement. Le fichier est écrit
+// à part puis renommé par Commit : un lecteur ne voit jamais un extrait
+// incomplet. Une erreur d'écriture (disque plein, extrait trop volumineux)
+// n'interrompt pas le flux lu à travers un io.TeeReader : elle est retournée
+// par Commit.
+type Writer struct {
+	c     *Cache
+	e     Entry
+	tmp   *os.File
+	limit int64
+	n     int64
+	err   error
+}
+
+// Create prépare l'écriture d'un extrait d'au plus limit octets
+func (c *Cache) Create(e Entry, limit int64) (*Writer, error) {
+	e.key = cacheKey(e.URL)
+	if limit <= 0 || limit > c.maxBytes {
+		limit = c.maxBytes
+	}
+	tmp, err := os.CreateTemp(c.dir, e.key+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{c: c, e: e, tmp: tmp, limit: limit}, nil
+}
+
+// Write écrit b dans le fichier temporaire ; après une erreur, les octets
+// suivants sont ignorés
+func (w *Writer) Write(b []byte) (int, error) {
+	if w.err == nil {
+		if w.n+int64(len(b)) > w.limit {
+			w.err = ErrTooLarge
+		} else {
+			n, err := w.tmp.Write(b)
+			w.n += int64(n)
+			w.err = err
+		}
+	}
+	return len(b), nil
+}
+
+// Abort abandonne l'écriture (téléchargement interrompu)
+func (w *Writer) Abort() {
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+}
+
+// Commit ajoute l'extrait écrit au cache et retourne son entrée
+func (w *Writer) Commit() (*Entry, error) {
+	c, e := w.c, w.e
+	defer os.Remove(w.tmp.Name())
+
+	err := w.tmp.Close()
+	if w.err != nil {
+		err = w.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Size = w.n
+
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(w.tmp.Name(), c.dataPath(e.key)); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.metaPath(e.key), meta, 0o644); err != nil {
+		return nil, err
+	}
+
+	if el, ok := c.entries[e.key]; ok {
+		c.size -= el.Value.(*Entry).Size
+		c.lru.Remove(el)
+	}
+	stored := e
+	c.entries[e.key] = c.lru.PushFront(&stored)
+	c.size += e.Size
+	c.evict(e.key)
+	return &e, nil
+}
+
+// Touch enregistre une revalidation réussie (réponse 304 du CDN)
+func (c *Cache) Touch(url string, checkedAt time.Time) {
+	key := cacheKey(url)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	e := el.Value.(*Entry)
+	e.CheckedAt = checkedAt
+	if meta, err := json.Marshal(e); err == nil {
+		os.WriteFile(c.metaPath(key), meta, 0o644)
+	}
+}
+
+// Remove supprime un extrait du cache (disparu du CDN)
+func (c *Cache) Remove(url string) {
+	key := cacheKey(url)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.size -= el.Value.(*Entry).Size
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+	c.removeFiles(key)
+}
+
+// evict supprime les extraits les moins récemment utilisés jusqu'à repasser
+// sous maxBytes, sans toucher à keep. Appelé avec c.mu verrouillé.
+func (c *Cache) evict(keep string) {
+	for c.size > c.maxBytes {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+		e := el.Value.(*Entry)
+		if e.key == keep {
+			if c.lru.Len() == 1 {
+				return
+			}
+			c.lru.MoveToFront(el)
+			continue
+		}
+		c.lru.Remove(el)
+		delete(c.entries, e.key)
+		c.size -= e.Size
+		c.removeFiles(e.key)
+	}
+}
+
+func (c *Cache) removeFiles(key string) {
+	os.Remove(c.dataPath(key))
+	os.Remove(c.metaPath(key))
+}
+
+func (c *Cache) dataPath(key string) string { return filepath.Join(c.dir, key+".audio") }
+func (c *Cache) metaPath(key string) string { return filepath.Join(c.dir, key+".json") }
+
+// cacheKey dérive un nom de fichier de l'URL de l'extrait
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,120 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrHostNotAllowed est retourné pour une URL hors de la liste des hôtes autorisés
+var ErrHostNotAllowed = errors.New("audio: hôte non autorisé")
+
+// ErrForbiddenAddress est retourné quand un hôte autorisé se résout vers une
+// adresse interne (loopback, réseau privé, métadonnées cloud...)
+var ErrForbiddenAddress = errors.New("audio: adresse interne refusée")
+
+// reserved liste les plages non routables sur Internet absentes des
+// méthodes de netip.Addr (IsPrivate, IsLoopback...)
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // « ce réseau » (0.0.0.0 atteint l'hôte local)
+	netip.MustParsePrefix("100.64.0.0/10"),  // NAT opérateur
+	netip.MustParsePrefix("198.18.0.0/15"),  // bancs de test
+	netip.MustParsePrefix("240.0.0.0/4"),    // réservé, diffusion 255.255.255.255
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64 local
+}
+
+// nat64 est le préfixe NAT64 bien connu : 64:ff9b::10.0.0.1 atteint 10.0.0.1
+var nat64 = netip.MustParsePrefix("64:ff9b::/96")
+
+// HostList est une liste d'hôtes autorisés : "audio-ssl.itunes.apple.com"
+// n'autorise que cet hôte, ".dzcdn.net" autorise tous ses sous-domaines
+type HostList []string
+
+// ParseHostList lit une liste séparée par des virgules (ex: AUDIO_PROXY_HOSTS)
+func ParseHostList(s string) HostList {
+	var hosts HostList
+	for _, h := range strings.Split(s, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// Allows indique si host fait partie de la liste
+func (l HostList) Allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range l {
+		if strings.HasPrefix(allowed, ".") {
+			if strings.HasSuffix(host, allowed) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL vérifie le schéma, le port et l'hôte d'une URL à récupérer
+func (l HostList) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: schéma %q", ErrHostNotAllowed, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: identifiants dans l'URL", ErrHostNotAllowed)
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return fmt.Errorf("%w: port %s", ErrHostNotAllowed, port)
+	}
+	if !l.Allows(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// checkAddress refuse les adresses qui ne sont pas publiques
+func checkAddress(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || isReserved(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	if nat64.Contains(ip) {
+		// Vérifier l'adresse IPv4 que la passerelle NAT64 contacterait
+		b := ip.As16()
+		if err := checkAddress(netip.AddrFrom4([4]byte(b[12:]))); err != nil {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+		}
+	}
+	return nil
+}
+
+func isReserved(ip netip.Addr) bool {
+	for _, p := range reserved {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialControl est appelé par le net.Dialer avec l'adresse IP effectivement
+// contactée, après résolution DNS : un nom public qui pointe vers une adresse
+// interne (ou qui change entre deux résolutions) est refusé
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return checkAddress(ip)
+}
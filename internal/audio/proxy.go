@@ -0,0 +1,566 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+)
+
+// DefaultTimeout est la durée maximale d'un téléchargement si aucune n'est fournie
+const DefaultTimeout = 15 * time.Second
+
+// DefaultMaxBytes est la taille maximale d'un extrait audio si aucune n'est fournie
+const DefaultMaxBytes = 10 << 20
+
+// maxRedirects limite le nombre de redirections suivies
+const maxRedirects = 5
+
+// revalidateAfter est la durée pendant laquelle un extrait en cache est servi
+// sans interroger le CDN ; au-delà, il est revalidé (ETag / Last-Modified)
+const revalidateAfter = time.Hour
+
+// bytesServed compte les octets d'extraits envoyés : "hit", "miss",
+// "revalidated" et "stale" depuis le cache, "bypass" relayés sans cache
+var bytesServed = metrics.Default.Counter("audio_proxy_bytes_served_total",
+	"Octets d'extraits audio envoyés par le proxy, par provenance", "source")
+
+// errNotAudio est retourné quand le CDN ne renvoie pas de l'audio
+var errNotAudio = errors.New("audio: contenu non audio")
+
+// userAgent est envoyé en amont : certaines API refusent les requêtes sans User-Agent
+const userAgent = "Mozilla/5.0 (compatible; GroupieProxy/1.0)"
+
+// Proxy relaie les extraits audio des CDN autorisés (iTunes, Deezer) pour
+// contourner le CORS, sans permettre d'atteindre d'autres hôtes
+type Proxy struct {
+	hosts    HostList
+	maxBytes int64
+	client   *http.Client
+	cache    *Cache // nil : pas de cache disque
+
+	// inflight contient les téléchargements en cours par clé du cache ;
+	// le canal est fermé à la fin du téléchargement
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+// NewProxy crée un proxy limité à hosts, aux réponses de maxBytes octets
+// et aux téléchargements de timeout. Les extraits sont gardés dans cache
+// s'il n'est pas nil.
+func NewProxy(hosts HostList, maxBytes int64, timeout time.Duration, cache *Cache) *Proxy {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialControl}
+	p := &Proxy{hosts: hosts, maxBytes: maxBytes, cache: cache, inflight: make(map[string]chan struct{})}
+	p.client = &http.Client{
+		Timeout: timeout,
+		Transport: metrics.Transport("audio_cdn", logging.Transport(&http.Transport{
+			// Pas de proxy HTTP : le contrôle d'adresse porte sur la connexion directe
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   4,
+			IdleConnTimeout:       90 * time.Second,
+		})),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("audio: plus de %d redirections", maxRedirects)
+			}
+			// Chaque redirection doit rester dans la liste des hôtes autorisés
+			return p.hosts.checkURL(req.URL)
+		},
+	}
+	return p
+}
+
+// forwardedHeaders sont recopiés de la requête du navigateur vers le CDN
+// (lecture partielle pour la navigation dans l'extrait)
+var forwardedHeaders = []string{"Range", "If-Range"}
+
+// relayedHeaders sont recopiés de la réponse du CDN vers le navigateur ;
+// ETag et Last-Modified permettent au navigateur d'envoyer If-Range
+var relayedHeaders = []string{"Content-Range", "Accept-Ranges", "ETag", "Last-Modified"}
+
+// ServeHTTP gère GET et HEAD /api/audio-proxy?url=<extrait>, avec Range
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	raw := r.URL.Query().Get("url")
+	if raw == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+	target, err := url.Parse(raw)
+	if err != nil || target.Host == "" {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+	if err := p.hosts.checkURL(target); err != nil {
+		logging.FromContext(r.Context()).Warn("audio-proxy: URL refusée", "err", err)
+		http.Error(w, "host not allowed", http.StatusForbidden)
+		return
+	}
+
+	// Octets envoyés au navigateur, par provenance (en-tête X-Cache)
+	cw := &countingWriter{ResponseWriter: w}
+	defer func() {
+		source := strings.ToLower(cw.Header().Get("X-Cache"))
+		if source == "" {
+			source = "bypass"
+		}
+		bytesServed.Add(float64(cw.n), source)
+	}()
+
+	// Un HEAD sur un extrait absent du cache est relayé sans le télécharger
+	if p.cache != nil {
+		if entry, ok := p.cache.Get(target.String()); ok || r.Method == http.MethodGet {
+			p.serveCached(cw, r, target, entry)
+			return
+		}
+	}
+	p.forward(cw, r, target)
+}
+
+// countingWriter compte les octets du corps de la réponse
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
+
+// serveCached sert un extrait depuis le cache disque, après l'avoir téléchargé
+// ou revalidé si nécessaire. Si le CDN est injoignable, la copie en cache est
+// servie telle quelle. Un seul téléchargement par extrait est fait à la fois :
+// les requêtes concurrentes attendent sa fin puis lisent le cache.
+func (p *Proxy) serveCached(w http.ResponseWriter, r *http.Request, target *url.URL, entry *Entry) {
+	if entry != nil && time.Since(entry.CheckedAt) < revalidateAfter {
+		p.serveEntry(w, r, target, entry, "HIT")
+		return
+	}
+
+	key := cacheKey(target.String())
+	done, ok := p.begin(key)
+	if !ok {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+			return
+		}
+		p.serveAfter(w, r, target)
+		return
+	}
+	defer p.end(key, done)
+
+	req, err := p.newRequest(r, http.MethodGet, target)
+	if err != nil {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if isForbidden(err) {
+			logging.FromContext(r.Context()).Warn("audio-proxy: URL refusée", "err", err)
+			http.Error(w, "host not allowed", http.StatusForbidden)
+			return
+		}
+		if entry != nil {
+			logging.FromContext(r.Context()).Warn("audio-proxy: CDN injoignable, extrait servi depuis le cache", "host", target.Host, "err", err)
+			p.serveEntry(w, r, target, entry, "STALE")
+			return
+		}
+		logging.FromContext(r.Context()).Error("audio-proxy: CDN injoignable", "host", target.Host, "err", err)
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		now := time.Now()
+		p.cache.Touch(target.String(), now)
+		entry.CheckedAt = now
+		p.serveEntry(w, r, target, entry, "REVALIDATED")
+
+	case resp.StatusCode == http.StatusOK:
+		e, body, err := p.download(target, resp)
+		if err == nil && streamable(r) {
+			p.stream(w, r, target, e, body, resp.ContentLength)
+			return
+		}
+		var stored *Entry
+		if err == nil {
+			stored, err = p.cache.Put(e, body, p.maxBytes)
+		}
+		switch {
+		case err == nil:
+			p.serveEntry(w, r, target, stored, "MISS")
+		case errors.Is(err, errNotAudio):
+			logging.FromContext(r.Context()).Warn("audio-proxy: contenu non audio", "host", target.Host, "content_type", resp.Header.Get("Content-Type"))
+			http.Error(w, "not audio", http.StatusBadGateway)
+		case errors.Is(err, ErrTooLarge):
+			http.Error(w, "audio too large", http.StatusBadGateway)
+		case entry != nil:
+			logging.FromContext(r.Context()).Warn("audio-proxy: mise en cache échouée, ancienne copie servie", "host", target.Host, "err", err)
+			p.serveEntry(w, r, target, entry, "STALE")
+		default:
+			logging.FromContext(r.Context()).Error("audio-proxy: mise en cache échouée", "host", target.Host, "err", err)
+			http.Error(w, "upstream error", http.StatusBadGateway)
+		}
+
+	case resp.StatusCode >= 500 && entry != nil:
+		logging.FromContext(r.Context()).Warn("audio-proxy: erreur du CDN, extrait servi depuis le cache", "host", target.Host, "status", resp.StatusCode)
+		p.serveEntry(w, r, target, entry, "STALE")
+
+	default:
+		// 404, 410... : l'extrait a disparu du CDN
+		if entry != nil && resp.StatusCode < 500 {
+			p.cache.Remove(target.String())
+		}
+		http.Error(w, "upstream error", http.StatusBadGateway)
+	}
+}
+
+// serveAfter répond à une requête qui a attendu le téléchargement concurrent
+// du même extrait : copie en cache (même ancienne si le CDN a échoué), sinon
+// relais direct au CDN sans nouvelle mise en cache
+func (p *Proxy) serveAfter(w http.ResponseWriter, r *http.Request, target *url.URL) {
+	entry, ok := p.cache.Get(target.String())
+	switch {
+	case !ok:
+		p.forward(w, r, target)
+	case time.Since(entry.CheckedAt) < revalidateAfter:
+		p.serveEntry(w, r, target, entry, "HIT")
+	default:
+		p.serveEntry(w, r, target, entry, "STALE")
+	}
+}
+
+// begin réserve le téléchargement de key ; s'il est déjà en cours, retourne
+// false et le canal fermé à sa fin
+func (p *Proxy) begin(key string) (chan struct{}, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if done, ok := p.inflight[key]; ok {
+		return done, false
+	}
+	done := make(chan struct{})
+	p.inflight[key] = done
+	return done, true
+}
+
+// end termine le téléchargement de key et libère les requêtes en attente
+func (p *Proxy) end(key string, done chan struct{}) {
+	p.mu.Lock()
+	delete(p.inflight, key)
+	p.mu.Unlock()
+	close(done)
+}
+
+// download vérifie qu'une réponse 200 du CDN est de l'audio et retourne
+// l'entrée à mettre en cache et le corps complet de l'extrait
+func (p *Proxy) download(target *url.URL, resp *http.Response) (Entry, io.Reader, error) {
+	if resp.ContentLength > p.maxBytes {
+		return Entry{}, nil, ErrTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Entry{}, nil, err
+	}
+	head = head[:n]
+	contentType, ok := audioType(resp.Header.Get("Content-Type"), head, target.Path)
+	if !ok {
+		return Entry{}, nil, errNotAudio
+	}
+
+	return Entry{
+		URL:          target.String(),
+		ContentType:  contentType,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		CheckedAt:    time.Now(),
+	}, io.MultiReader(bytes.NewReader(head), resp.Body), nil
+}
+
+// streamable indique si la réponse à r peut être l'extrait complet envoyé
+// pendant son téléchargement : GET sans Range, ou "bytes=0-" que les
+// navigateurs envoient à la première lecture (une réponse 200 est valide)
+func streamable(r *http.Request) bool {
+	rng := r.Header.Get("Range")
+	return r.Method == http.MethodGet && (rng == "" || rng == "bytes=0-")
+}
+
+// stream envoie au navigateur un extrait au fil de son téléchargement et
+// l'écrit en parallèle dans le cache ; un téléchargement interrompu (CDN,
+// navigateur parti) n'est pas mis en cache
+func (p *Proxy) stream(w http.ResponseWriter, r *http.Request, target *url.URL, e Entry, body io.Reader, size int64) {
+	log := logging.FromContext(r.Context())
+	cw, err := p.cache.Create(e, p.maxBytes)
+	if err != nil {
+		log.Warn("audio-proxy: mise en cache impossible", "err", err)
+	}
+
+	w.Header().Set("Content-Type", e.ContentType)
+	if size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if e.ETag != "" {
+		w.Header().Set("ETag", e.ETag)
+	}
+	if e.LastModified != "" {
+		w.Header().Set("Last-Modified", e.LastModified)
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("X-Cache", "MISS")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	src := io.LimitReader(body, p.maxBytes)
+	if cw != nil {
+		src = io.TeeReader(src, cw)
+	}
+	copied, err := io.Copy(w, src)
+	if err == nil && copied == p.maxBytes && size < 0 {
+		if n, _ := io.ReadFull(body, make([]byte, 1)); n > 0 {
+			log.Warn("audio-proxy: extrait tronqué", "host", target.Host, "max_bytes", p.maxBytes)
+			err = ErrTooLarge
+		}
+	}
+	if cw == nil {
+		return
+	}
+	if err != nil {
+		cw.Abort()
+		return
+	}
+	if _, err := cw.Commit(); err != nil {
+		log.Warn("audio-proxy: mise en cache échouée", "err", err)
+	}
+}
+
+// serveEntry sert un extrait du cache ; http.ServeContent gère HEAD, Range,
+// If-Range et les requêtes conditionnelles. Si le fichier a disparu entre
+// temps (éviction LRU), la requête est relayée au CDN.
+func (p *Proxy) serveEntry(w http.ResponseWriter, r *http.Request, target *url.URL, e *Entry, status string) {
+	f, err := p.cache.Open(e)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("audio-proxy: extrait absent du cache, relayé au CDN", "err", err)
+		p.forward(w, r, target)
+		return
+	}
+	defer f.Close()
+
+	modTime, _ := http.ParseTime(e.LastModified)
+	w.Header().Set("Content-Type", e.ContentType)
+	if e.ETag != "" {
+		w.Header().Set("ETag", e.ETag)
+	}
+	w.Header().Set("X-Cache", status)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", modTime, f)
+}
+
+// forward relaie directement la requête au CDN (sans cache, ou HEAD sur un
+// extrait absent du cache)
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request, target *url.URL) {
+	req, err := p.newRequest(r, r.Method, target)
+	if err != nil {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+	for _, h := range forwardedHeaders {
+		if v := r.Header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if isForbidden(err) {
+			logging.FromContext(r.Context()).Warn("audio-proxy: URL refusée", "err", err)
+			http.Error(w, "host not allowed", http.StatusForbidden)
+			return
+		}
+		logging.FromContext(r.Context()).Error("audio-proxy: CDN injoignable", "host", target.Host, "err", err)
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Plage hors du fichier : le navigateur relit la taille dans Content-Range
+		copyHeaders(w.Header(), resp.Header, relayedHeaders)
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	default:
+		http.Error(w, "upstream error", http.StatusBadGateway)
+		return
+	}
+	if resp.ContentLength > p.maxBytes {
+		http.Error(w, "audio too large", http.StatusBadGateway)
+		return
+	}
+
+	// Vérifier que le corps est bien de l'audio : type déclaré, signature du
+	// début du fichier ou, à défaut (HEAD, plage au milieu du fichier),
+	// extension de l'URL
+	var head []byte
+	if r.Method == http.MethodGet {
+		head = make([]byte, 512)
+		n, err := io.ReadFull(resp.Body, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			http.Error(w, "upstream error", http.StatusBadGateway)
+			return
+		}
+		head = head[:n]
+	}
+	sniff := head
+	if resp.StatusCode == http.StatusPartialContent && !startsAtZero(resp.Header.Get("Content-Range")) {
+		sniff = nil
+	}
+	contentType, ok := audioType(resp.Header.Get("Content-Type"), sniff, target.Path)
+	if !ok {
+		logging.FromContext(r.Context()).Warn("audio-proxy: contenu non audio", "host", target.Host, "content_type", resp.Header.Get("Content-Type"))
+		http.Error(w, "not audio", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if resp.ContentLength >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+	copyHeaders(w.Header(), resp.Header, relayedHeaders)
+	if w.Header().Get("Accept-Ranges") == "" && resp.StatusCode == http.StatusPartialContent {
+		w.Header().Set("Accept-Ranges", "bytes")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(resp.StatusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), resp.Body)
+	copied, err := io.Copy(w, io.LimitReader(body, p.maxBytes))
+	if err == nil && copied == p.maxBytes && resp.ContentLength < 0 {
+		logging.FromContext(r.Context()).Warn("audio-proxy: extrait tronqué", "host", target.Host, "max_bytes", p.maxBytes)
+	}
+}
+
+// newRequest prépare la requête vers le CDN, annulée avec celle du navigateur
+func (p *Proxy) newRequest(r *http.Request, method string, target *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(r.Context(), method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+// isForbidden indique si err provient du contrôle des hôtes ou des adresses
+func isForbidden(err error) bool {
+	return errors.Is(err, ErrHostNotAllowed) || errors.Is(err, ErrForbiddenAddress)
+}
+
+// copyHeaders recopie les en-têtes names de src vers dst
+func copyHeaders(dst, src http.Header, names []string) {
+	for _, h := range names {
+		if v := src.Get(h); v != "" {
+			dst.Set(h, v)
+		}
+	}
+}
+
+// startsAtZero indique si un Content-Range ("bytes 0-1023/4096") commence au début du fichier
+func startsAtZero(contentRange string) bool {
+	return strings.HasPrefix(contentRange, "bytes 0-")
+}
+
+// audioType retourne le Content-Type à renvoyer si la réponse est de l'audio :
+// type audio/* déclaré, ou type générique et signature (head) ou extension
+// de urlPath reconnue
+func audioType(declared string, head []byte, urlPath string) (string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(declared)
+	switch {
+	case strings.HasPrefix(mediaType, "audio/"):
+		return mediaType, true
+	case mediaType == "" || mediaType == "application/octet-stream" || mediaType == "binary/octet-stream":
+		if sniffed := sniffAudio(head); sniffed != "" {
+			return sniffed, true
+		}
+		if head == nil {
+			if t, ok := audioExtensions[strings.ToLower(path.Ext(urlPath))]; ok {
+				return t, true
+			}
+		}
+	}
+	return "", false
+}
+
+// audioExtensions associe les extensions des extraits à leur type
+var audioExtensions = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".wav":  "audio/wav",
+}
+
+// sniffAudio reconnaît les formats d'extraits usuels par leur signature
+func sniffAudio(b []byte) string {
+	switch {
+	case bytes.HasPrefix(b, []byte("ID3")):
+		return "audio/mpeg"
+	case len(b) >= 2 && b[0] == 0xFF && b[1]&0xE0 == 0xE0:
+		return "audio/mpeg"
+	case len(b) >= 12 && bytes.Equal(b[4:8], []byte("ftyp")):
+		return "audio/mp4"
+	case bytes.HasPrefix(b, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(b, []byte("fLaC")):
+		return "audio/flac"
+	case len(b) >= 12 && bytes.HasPrefix(b, []byte("RIFF")) && bytes.Equal(b[8:12], []byte("WAVE")):
+		return "audio/wav"
+	}
+	return ""
+}
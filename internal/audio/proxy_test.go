@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowCDN répond un extrait MP3 après un délai et compte ses requêtes
+type slowCDN struct {
+	calls atomic.Int32
+}
+
+func (c *slowCDN) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	body := "ID3" + strings.Repeat("a", 1021)
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"audio/mpeg"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func TestProxyCollapsesConcurrentMisses(t *testing.T) {
+	cache, err := NewCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewProxy(HostList{"cdn.example"}, 0, 0, cache)
+	cdn := &slowCDN{}
+	p.client.Transport = cdn
+
+	const clients = 5
+	var wg sync.WaitGroup
+	codes := make([]int, clients)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/audio-proxy?url=https://cdn.example/preview.mp3", nil))
+			if w.Body.Len() != 1024 {
+				t.Errorf("client %d: %d octets, attendu 1024", i, w.Body.Len())
+			}
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	if n := cdn.calls.Load(); n != 1 {
+		t.Errorf("%d téléchargements, attendu 1", n)
+	}
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("client %d: statut %d, attendu 200", i, code)
+		}
+	}
+}
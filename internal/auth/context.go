@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/models"
+)
+
+type contextKey int
+
+const userKey contextKey = iota
+
+// WithUser retourne un contexte portant l'utilisateur connecté ; son
+// identifiant est ajouté aux logs de la requête
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	ctx = logging.WithUser(ctx, user.ID)
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext retourne l'utilisateur connecté, ou nil pour un visiteur anonyme
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey).(*models.User)
+	return user
+}
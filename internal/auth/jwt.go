@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/models"
+)
+
+// Durées de vie des jetons
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Types de jetons (claim "typ")
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// ErrInvalidToken est retourné pour un jeton mal formé, mal signé, expiré ou révoqué
+var ErrInvalidToken = errors.New("jeton invalide ou expiré")
+
+// jwtHeader est l'en-tête fixe des jetons émis (HMAC-SHA256)
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims est le contenu d'un jeton JWT
+type Claims struct {
+	Subject   string `json:"sub"`
+	Type      string `json:"typ"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// UserID retourne l'identifiant utilisateur du jeton
+func (c *Claims) UserID() (int, error) {
+	return strconv.Atoi(c.Subject)
+}
+
+// TokenPair est la réponse de POST /api/token et /api/token/refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Tokens émet et vérifie des jetons JWT signés avec JWT_SECRET.
+// Les jetons révoqués sont listés dans la table revoked_tokens jusqu'à leur expiration.
+type Tokens struct {
+	db     *sql.DB
+	users  *Users
+	secret []byte
+}
+
+// NewTokens crée un émetteur de jetons. Sans secret, une clé aléatoire est
+// générée : les jetons émis ne survivent alors pas à un redémarrage.
+func NewTokens(db *sql.DB, users *Users, secret string) *Tokens {
+	key := []byte(secret)
+	if len(key) == 0 {
+		slog.Warn("JWT_SECRET absent : clé JWT aléatoire (jetons invalidés au redémarrage)")
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &Tokens{db: db, users: users, secret: key}
+}
+
+// Issue émet un jeton d'accès et un jeton de rafraîchissement pour user
+func (t *Tokens) Issue(user *models.User) (*TokenPair, error) {
+	access, err := t.sign(user.ID, TokenAccess, AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := t.sign(user.ID, TokenRefresh, RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Verify contrôle la signature, l'expiration, le type et la révocation d'un jeton
+func (t *Tokens) Verify(ctx context.Context, token, typ string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	if parts[0] != jwtHeader || !hmac.Equal([]byte(parts[2]), []byte(t.mac(parts[0]+"."+parts[1]))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != typ || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	var revoked bool
+	if err := t.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`, claims.ID).Scan(&revoked); err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// Refresh échange un jeton de rafraîchissement contre une nouvelle paire.
+// L'ancien jeton de rafraîchissement est révoqué (rotation).
+func (t *Tokens) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := t.Verify(ctx, refreshToken, TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+	id, err := claims.UserID()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := t.users.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Seul l'appel qui insère la révocation obtient une nouvelle paire : deux
+	// rafraîchissements concurrents du même jeton n'en émettent pas deux
+	revoked, err := t.revoke(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrInvalidToken
+	}
+	return t.Issue(user)
+}
+
+// Revoke ajoute un jeton (accès ou rafraîchissement) à la liste de révocation
+func (t *Tokens) Revoke(ctx context.Context, token string) error {
+	claims, err := t.Verify(ctx, token, TokenAccess)
+	if errors.Is(err, ErrInvalidToken) {
+		claims, err = t.Verify(ctx, token, TokenRefresh)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = t.revoke(ctx, claims)
+	return err
+}
+
+// Middleware authentifie les requêtes portant "Authorization: Bearer <jeton d'accès>"
+// et place l'utilisateur dans le contexte. Un jeton invalide donne 401.
+func (t *Tokens) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := t.user(r.Context(), token)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidToken) {
+				logging.FromContext(r.Context()).Warn("Erreur vérification jeton", "err", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Jeton invalide ou expiré"})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+	})
+}
+
+// user retourne l'utilisateur d'un jeton d'accès
+func (t *Tokens) user(ctx context.Context, token string) (*models.User, error) {
+	claims, err := t.Verify(ctx, token, TokenAccess)
+	if err != nil {
+		return nil, err
+	}
+	id, err := claims.UserID()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := t.users.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	return user, err
+}
+
+// revoke enregistre le jti du jeton et purge les révocations expirées ;
+// false si le jeton était déjà révoqué
+func (t *Tokens) revoke(ctx context.Context, claims *Claims) (bool, error) {
+	if _, err := t.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		logging.FromContext(ctx).Warn("Erreur purge jetons révoqués", "err", err)
+	}
+	userID, _ := claims.UserID()
+	res, err := t.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`, claims.ID, userID, time.Unix(claims.ExpiresAt, 0).UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// sign construit et signe un jeton
+func (t *Tokens) sign(userID int, typ string, ttl time.Duration) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(Claims{
+		Subject:   strconv.Itoa(userID),
+		Type:      typ,
+		ID:        hex.EncodeToString(jti),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("encodage du jeton: %w", err)
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + t.mac(unsigned), nil
+}
+
+// mac calcule la signature HS256 d'un jeton
+func (t *Tokens) mac(unsigned string) string {
+	m := hmac.New(sha256.New, t.secret)
+	m.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
+
+// bearerToken extrait le jeton de l'en-tête Authorization
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(h[7:])
+	return token, token != ""
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/models"
+)
+
+// Durées de vie des sessions
+const (
+	SessionTTL         = 24 * time.Hour
+	RememberSessionTTL = 30 * 24 * time.Hour
+)
+
+// SessionCookie est le nom du cookie de session
+const SessionCookie = "groupie_session"
+
+// ErrNoSession est retourné quand la requête ne porte pas de session valide
+var ErrNoSession = errors.New("session absente, expirée ou révoquée")
+
+// Sessions gère les sessions côté serveur (table sessions) et leurs cookies signés.
+// Le cookie contient un jeton aléatoire signé HMAC-SHA256 avec SESSION_SECRET ;
+// la base ne stocke que l'empreinte SHA-256 du jeton.
+type Sessions struct {
+	db     *sql.DB
+	users  *Users
+	secret []byte
+}
+
+// NewSessions crée un gestionnaire de sessions. Sans secret, une clé aléatoire
+// est générée : les sessions ne survivent alors pas à un redémarrage.
+func NewSessions(db *sql.DB, users *Users, secret string) *Sessions {
+	key := []byte(secret)
+	if len(key) == 0 {
+		slog.Warn("SESSION_SECRET absent : clé de session aléatoire (sessions perdues au redémarrage)")
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &Sessions{db: db, users: users, secret: key}
+}
+
+// Create ouvre une session pour user et pose le cookie sur la réponse
+func (s *Sessions) Create(ctx context.Context, w http.ResponseWriter, r *http.Request, user *models.User, remember bool) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ttl := SessionTTL
+	if remember {
+		ttl = RememberSessionTTL
+	}
+	expires := time.Now().Add(ttl)
+
+	// Profiter de la connexion pour purger les sessions expirées de l'utilisateur
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1 AND expires_at < NOW()`, user.ID); err != nil {
+		logging.FromContext(ctx).Warn("Erreur purge sessions", "err", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, hashToken(token), user.ID, expires.UTC()); err != nil {
+		return err
+	}
+
+	cookie := &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token + "." + s.sign(token),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+	// Sans "se souvenir de moi", cookie de session navigateur (expiration serveur à 24h)
+	if remember {
+		cookie.Expires = expires
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// Revoke révoque la session de la requête et efface le cookie
+func (s *Sessions) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	token, ok := s.token(r)
+	if !ok {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, hashToken(token))
+	return err
+}
+
+// User retourne l'utilisateur de la session portée par la requête
+func (s *Sessions) User(ctx context.Context, r *http.Request) (*models.User, error) {
+	token, ok := s.token(r)
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	var userID int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id FROM sessions
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	`, hashToken(token)).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.users.Get(ctx, userID)
+}
+
+// Middleware place l'utilisateur de la session dans le contexte de la requête
+// (voir UserFromContext). Les requêtes sans session passent en anonyme.
+func (s *Sessions) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := s.User(r.Context(), r)
+		if err != nil && !errors.Is(err, ErrNoSession) {
+			logging.FromContext(r.Context()).Warn("Erreur lecture session", "err", err)
+		}
+		if user != nil {
+			r = r.WithContext(WithUser(r.Context(), user))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// token extrait le jeton du cookie après vérification de sa signature
+func (s *Sessions) token(r *http.Request) (string, bool) {
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "", false
+	}
+	token, sig, ok := strings.Cut(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(token))) {
+		return "", false
+	}
+	return token, true
+}
+
+// sign calcule la signature HMAC-SHA256 d'un jeton
+func (s *Sessions) sign(token string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// hashToken retourne l'empreinte stockée en base pour un jeton
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// isHTTPS indique si la requête est arrivée en HTTPS (directement ou via le proxy Scalingo)
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"groupiepersso/internal/models"
+)
+
+// Contraintes sur les champs d'inscription
+const (
+	MinPasswordLength = 8
+	maxPasswordBytes  = 72 // limite de bcrypt
+	maxNameLength     = 100
+)
+
+// ErrInvalidCredentials est retourné quand l'identifiant ou le mot de passe est faux
+var ErrInvalidCredentials = errors.New("identifiant ou mot de passe incorrect")
+
+// ValidationError liste les champs invalides d'une requête, par nom de champ
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return "données invalides"
+}
+
+// Registration contient les données du formulaire d'inscription
+type Registration struct {
+	Nom      string `json:"nom"`
+	Prenom   string `json:"prenom"`
+	Sexe     string `json:"sexe"`
+	Password string `json:"password"`
+}
+
+// Validate vérifie les champs et retourne une *ValidationError si besoin
+func (r *Registration) Validate() error {
+	r.Nom = strings.TrimSpace(r.Nom)
+	r.Prenom = strings.TrimSpace(r.Prenom)
+	r.Sexe = strings.TrimSpace(r.Sexe)
+
+	fields := make(map[string]string)
+	checkName := func(key, value string) {
+		switch {
+		case value == "":
+			fields[key] = "requis"
+		case utf8.RuneCountInString(value) > maxNameLength:
+			fields[key] = fmt.Sprintf("%d caractères maximum", maxNameLength)
+		}
+	}
+	checkName("nom", r.Nom)
+	checkName("prenom", r.Prenom)
+
+	switch r.Sexe {
+	case "", "F", "M", "Autre":
+	default:
+		fields["sexe"] = "valeur invalide (F, M ou Autre)"
+	}
+
+	switch {
+	case utf8.RuneCountInString(r.Password) < MinPasswordLength:
+		fields["password"] = fmt.Sprintf("%d caractères minimum", MinPasswordLength)
+	case len(r.Password) > maxPasswordBytes:
+		fields["password"] = fmt.Sprintf("%d octets maximum", maxPasswordBytes)
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// Users gère les comptes stockés dans la table users
+type Users struct {
+	db *sql.DB
+}
+
+// NewUsers crée un gestionnaire de comptes adossé à db
+func NewUsers(db *sql.DB) *Users {
+	return &Users{db: db}
+}
+
+// Register valide l'inscription, hache le mot de passe avec bcrypt et crée le compte
+func (u *Users) Register(ctx context.Context, reg Registration) (*models.User, error) {
+	if err := reg.Validate(); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(reg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hachage du mot de passe: %w", err)
+	}
+
+	user := &models.User{Nom: reg.Nom, Prenom: reg.Prenom, Sexe: reg.Sexe, PasswordHash: string(hash)}
+	err = u.db.QueryRowContext(ctx, `
+		INSERT INTO users (nom, prenom, sexe, password_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, user.Nom, user.Prenom, user.Sexe, user.PasswordHash).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("création du compte: %w", err)
+	}
+	return user, nil
+}
+
+// Get retourne un compte par son identifiant
+func (u *Users) Get(ctx context.Context, id int) (*models.User, error) {
+	var user models.User
+	var sexe sql.NullString
+	err := u.db.QueryRowContext(ctx, `
+		SELECT id, nom, prenom, sexe, password_hash, created_at
+		FROM users WHERE id = $1
+	`, id).Scan(&user.ID, &user.Nom, &user.Prenom, &sexe, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	user.Sexe = sexe.String
+	return &user, nil
+}
+
+// Authenticate vérifie l'identifiant et le mot de passe
+func (u *Users) Authenticate(ctx context.Context, id int, password string) (*models.User, error) {
+	user, err := u.Get(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Comparer quand même pour ne pas révéler l'existence du compte par le temps de réponse
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// dummyHash sert aux comparaisons pour les comptes inexistants
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("groupie-dummy-password"), bcrypt.DefaultCost)
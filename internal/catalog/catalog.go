@@ -0,0 +1,231 @@
+package catalog
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/groupie"
+	"groupiepersso/internal/logging"
+)
+
+// DefaultRefreshInterval est l'intervalle de rafraîchissement si aucun n'est configuré
+const DefaultRefreshInterval = 30 * time.Minute
+
+// retryInterval est le délai entre deux tentatives tant qu'aucune donnée n'est chargée
+const retryInterval = 30 * time.Second
+
+// Entry regroupe toutes les données d'un artiste jointes par identifiant
+type Entry struct {
+	Artist    groupie.Artist      `json:"artist"`
+	Locations []string            `json:"locations"`
+	Dates     []string            `json:"dates"`
+	Relations map[string][]string `json:"datesLocations"`
+}
+
+// Snapshot est une copie immuable du catalogue à un instant donné
+type Snapshot struct {
+	Artists   []groupie.Artist
+	Locations []groupie.Location
+	Dates     []groupie.Dates
+	Relations []groupie.Relation
+	Entries   []Entry
+	UpdatedAt time.Time
+	Version   uint64
+
+	byID map[int]int
+}
+
+// Entry retourne les données jointes d'un artiste
+func (s *Snapshot) Entry(id int) (*Entry, bool) {
+	i, ok := s.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return &s.Entries[i], true
+}
+
+// LocationNames retourne la liste triée et sans doublon des lieux de concerts
+func (s *Snapshot) LocationNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, l := range s.Locations {
+		for _, name := range l.Locations {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Status résume l'état du catalogue pour la supervision
+type Status struct {
+	Ready       bool      `json:"ready"`
+	Healthy     bool      `json:"healthy"`
+	Artists     int       `json:"artists"`
+	LastRefresh time.Time `json:"last_refresh,omitempty"`
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	AgeSeconds  float64   `json:"age_seconds"`
+}
+
+// Catalog garde en mémoire les données de l'API Groupie Trackers
+// et les rafraîchit périodiquement en arrière-plan
+type Catalog struct {
+	client   *groupie.Client
+	interval time.Duration
+
+	mu          sync.RWMutex
+	snap        *Snapshot
+	lastAttempt time.Time
+	lastErr     error
+}
+
+// New crée un catalogue vide alimenté par client
+func New(client *groupie.Client, interval time.Duration) *Catalog {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	return &Catalog{client: client, interval: interval}
+}
+
+// Snapshot retourne les dernières données chargées, ou nil si aucun chargement n'a réussi
+func (c *Catalog) Snapshot() *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snap
+}
+
+// Status retourne l'état courant du catalogue
+func (c *Catalog) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	st := Status{
+		Ready:       c.snap != nil,
+		Healthy:     c.snap != nil && c.lastErr == nil,
+		LastAttempt: c.lastAttempt,
+	}
+	if c.snap != nil {
+		st.Artists = len(c.snap.Artists)
+		st.LastRefresh = c.snap.UpdatedAt
+		st.AgeSeconds = time.Since(c.snap.UpdatedAt).Seconds()
+	}
+	if c.lastErr != nil {
+		st.LastError = c.lastErr.Error()
+	}
+	return st
+}
+
+// Run charge le catalogue puis le rafraîchit jusqu'à l'annulation de ctx
+func (c *Catalog) Run(ctx context.Context) {
+	for {
+		// Un identifiant par rafraîchissement, transmis à l'API Groupie Trackers
+		refreshCtx := logging.WithRequestID(ctx, logging.NewRequestID())
+		if err := c.Refresh(refreshCtx); err != nil && ctx.Err() == nil {
+			logging.FromContext(refreshCtx).Warn("Rafraîchissement du catalogue échoué (données précédentes conservées)", "err", err)
+		}
+
+		wait := c.interval
+		if c.Snapshot() == nil && retryInterval < wait {
+			wait = retryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Refresh recharge les quatre endpoints de l'API. En cas d'échec,
+// l'ancien snapshot reste servi.
+func (c *Catalog) Refresh(ctx context.Context) error {
+	var (
+		wg        sync.WaitGroup
+		artists   []groupie.Artist
+		locations []groupie.Location
+		dates     []groupie.Dates
+		relations []groupie.Relation
+		errs      [4]error
+	)
+
+	wg.Add(4)
+	go func() { defer wg.Done(); artists, errs[0] = c.client.Artists(ctx) }()
+	go func() { defer wg.Done(); locations, errs[1] = c.client.Locations(ctx) }()
+	go func() { defer wg.Done(); dates, errs[2] = c.client.Dates(ctx) }()
+	go func() { defer wg.Done(); relations, errs[3] = c.client.Relations(ctx) }()
+	wg.Wait()
+
+	var err error
+	for _, e := range errs {
+		if e != nil {
+			err = e
+			break
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastAttempt = time.Now()
+	c.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	var version uint64 = 1
+	if c.snap != nil {
+		version = c.snap.Version + 1
+	}
+	c.snap = build(artists, locations, dates, relations, version)
+	logging.FromContext(ctx).Info("Catalogue rafraîchi", "artists", len(artists))
+	return nil
+}
+
+// build joint les quatre listes par identifiant d'artiste
+func build(artists []groupie.Artist, locations []groupie.Location, dates []groupie.Dates, relations []groupie.Relation, version uint64) *Snapshot {
+	locByID := make(map[int][]string, len(locations))
+	for _, l := range locations {
+		locByID[l.ID] = l.Locations
+	}
+	datesByID := make(map[int][]string, len(dates))
+	for _, d := range dates {
+		datesByID[d.ID] = d.Dates
+	}
+	relByID := make(map[int]map[string][]string, len(relations))
+	for _, r := range relations {
+		relByID[r.ID] = r.DatesLocations
+	}
+
+	entries := make([]Entry, 0, len(artists))
+	for _, a := range artists {
+		entries = append(entries, Entry{
+			Artist:    a,
+			Locations: locByID[a.ID],
+			Dates:     datesByID[a.ID],
+			Relations: relByID[a.ID],
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Artist.ID < entries[j].Artist.ID })
+
+	byID := make(map[int]int, len(entries))
+	for i, e := range entries {
+		byID[e.Artist.ID] = i
+	}
+
+	return &Snapshot{
+		Artists:   artists,
+		Locations: locations,
+		Dates:     dates,
+		Relations: relations,
+		Entries:   entries,
+		UpdatedAt: time.Now(),
+		Version:   version,
+		byID:      byID,
+	}
+}
@@ -1,8 +0,0 @@
-package core
-
-// auth.go - Gestion de l'authentification
-// Ce fichier gère l'enregistrement et la connexion des utilisateurs
-
-func initAuth() {
-	// Initialisation de l'authentification
-}
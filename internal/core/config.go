@@ -2,29 +2,57 @@ package core
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config contient toutes les variables d'environnement de l'application
 type Config struct {
-	Port              string
-	Environment       string
-	DBHost            string
-	DBPort            string
-	DBUser            string
-	DBPassword        string
-	DBName            string
-	DatabaseURL       string // Pour Scalingo/production
-	GroupieTrackerAPI string
-	JWTSecret         string
-	SessionSecret     string
-	AllowedOrigins    string
-	LogLevel          string
+	Port                  string
+	HTTPReadHeaderTimeout time.Duration
+	HTTPReadTimeout       time.Duration
+	HTTPWriteTimeout      time.Duration // couvre aussi l'envoi des extraits audio
+	HTTPIdleTimeout       time.Duration
+	ShutdownTimeout       time.Duration // délai laissé aux requêtes en cours à l'arrêt
+	Environment           string
+	DBHost                string
+	DBPort                string
+	DBUser                string
+	DBPassword            string
+	DBName                string
+	DatabaseURL           string // Pour Scalingo/production
+	GroupieTrackerAPI     string
+	GroupieTimeout        time.Duration
+	CatalogRefresh        time.Duration
+	CatalogMaxAge         time.Duration // au-delà, le catalogue est signalé périmé par /readyz
+	Geocoder              string        // "nominatim", "fixture" ou "none"
+	GeocoderFixtures      string
+	GeocoderInterval      time.Duration
+	NominatimURL          string
+	NominatimAgent        string
+	JWTSecret             string
+	SessionSecret         string
+	AllowedOrigins        string
+	LogLevel              string
+	ReadyRequired         string // dépendances nécessaires à /readyz : "database", "groupie", "catalog"
+	MetricsToken          string // jeton Bearer exigé par /metrics (vide : /metrics désactivé)
+	AssetsDir             string // vide : pages et fichiers statiques embarqués dans le binaire
+	AudioProxyHosts       string // hôtes autorisés par /api/audio-proxy (".domaine" pour les sous-domaines)
+	AudioProxyMaxSize     int64
+	AudioProxyTimeout     time.Duration
+	AudioCacheDir         string
+	AudioCacheMaxSize     int64  // 0 : pas de cache disque des extraits
+	PreviewProviders      string // fournisseurs d'extraits dans l'ordre : "itunes", "deezer", "fixture"
+	PreviewFixtures       string
+	ITunesURL             string
+	DeezerURL             string
 }
 
 // LoadConfig charge les variables d'environnement
@@ -45,19 +73,44 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		Port:              getEnv("PORT", "8080"),
-		Environment:       getEnv("ENVIRONMENT", "production"),
-		DBHost:            getEnv("DB_HOST", "localhost"),
-		DBPort:            getEnv("DB_PORT", "5432"),
-		DBUser:            getEnv("DB_USER", "postgres"),
-		DBPassword:        getEnv("DB_PASSWORD", ""),
-		DBName:            getEnv("DB_NAME", "groupiepersso"),
-		DatabaseURL:       databaseURL,
-		GroupieTrackerAPI: getEnv("GROUPIE_TRACKERS_API", "https://groupietrackers.herokuapp.com/api"),
-		JWTSecret:         getEnv("JWT_SECRET", ""),
-		SessionSecret:     getEnv("SESSION_SECRET", ""),
-		AllowedOrigins:    getEnv("ALLOWED_ORIGINS", "*"),
-		LogLevel:          getEnv("LOG_LEVEL", "info"),
+		Port:                  getEnv("PORT", "8080"),
+		HTTPReadHeaderTimeout: getDurationEnv("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		HTTPReadTimeout:       getDurationEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+		HTTPWriteTimeout:      getDurationEnv("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		HTTPIdleTimeout:       getDurationEnv("HTTP_IDLE_TIMEOUT", 120*time.Second),
+		ShutdownTimeout:       getDurationEnv("SHUTDOWN_TIMEOUT", 25*time.Second),
+		Environment:           getEnv("ENVIRONMENT", "production"),
+		DBHost:                getEnv("DB_HOST", "localhost"),
+		DBPort:                getEnv("DB_PORT", "5432"),
+		DBUser:                getEnv("DB_USER", "postgres"),
+		DBPassword:            getEnv("DB_PASSWORD", ""),
+		DBName:                getEnv("DB_NAME", "groupiepersso"),
+		DatabaseURL:           databaseURL,
+		GroupieTrackerAPI:     getEnv("GROUPIE_TRACKERS_API", "https://groupietrackers.herokuapp.com/api"),
+		GroupieTimeout:        getDurationEnv("GROUPIE_TRACKERS_TIMEOUT", 10*time.Second),
+		CatalogRefresh:        getDurationEnv("CATALOG_REFRESH_INTERVAL", 30*time.Minute),
+		CatalogMaxAge:         getDurationEnv("CATALOG_MAX_AGE", 2*time.Hour),
+		Geocoder:              getEnv("GEOCODER", "nominatim"),
+		GeocoderFixtures:      getEnv("GEOCODER_FIXTURES", "fixtures/geo.json"),
+		GeocoderInterval:      getDurationEnv("GEOCODER_INTERVAL", time.Second),
+		NominatimURL:          getEnv("NOMINATIM_URL", "https://nominatim.openstreetmap.org"),
+		NominatimAgent:        getEnv("NOMINATIM_USER_AGENT", "GroupiePersso/1.0 (https://github.com/Claxid/Groupie-Persso)"),
+		JWTSecret:             getEnv("JWT_SECRET", ""),
+		SessionSecret:         getEnv("SESSION_SECRET", ""),
+		AllowedOrigins:        getEnv("ALLOWED_ORIGINS", "*"),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		ReadyRequired:         getEnv("READY_REQUIRED", "database,catalog"),
+		MetricsToken:          getEnv("METRICS_TOKEN", ""),
+		AssetsDir:             getEnv("ASSETS_DIR", ""),
+		AudioProxyHosts:       getEnv("AUDIO_PROXY_HOSTS", "audio-ssl.itunes.apple.com,.mzstatic.com,.dzcdn.net"),
+		AudioProxyMaxSize:     getInt64Env("AUDIO_PROXY_MAX_BYTES", 10<<20),
+		AudioProxyTimeout:     getDurationEnv("AUDIO_PROXY_TIMEOUT", 15*time.Second),
+		AudioCacheDir:         getEnv("AUDIO_CACHE_DIR", filepath.Join(os.TempDir(), "groupiepersso-audio")),
+		AudioCacheMaxSize:     getInt64Env("AUDIO_CACHE_MAX_BYTES", 200<<20),
+		PreviewProviders:      getEnv("PREVIEW_PROVIDERS", "itunes,deezer"),
+		PreviewFixtures:       getEnv("PREVIEW_FIXTURES", "fixtures/previews.json"),
+		ITunesURL:             getEnv("ITUNES_URL", "https://itunes.apple.com"),
+		DeezerURL:             getEnv("DEEZER_URL", "https://api.deezer.com"),
 	}
 }
 
@@ -66,18 +119,46 @@ func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
 		if key == "JWT_SECRET" || key == "SESSION_SECRET" && defaultValue == "" {
-			log.Printf("⚠️  WARNING: %s not set, using empty default. Set this in production!", key)
+			slog.Warn("Variable absente, valeur vide utilisée : à définir en production", "key", key)
 		}
 		return defaultValue
 	}
 	return value
 }
 
+// getDurationEnv lit une durée (ex: "30s", "5m") avec une valeur par défaut
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Durée invalide, valeur par défaut utilisée", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getInt64Env lit un entier (ex: une taille en octets) avec une valeur par défaut
+func getInt64Env(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		slog.Warn("Entier invalide, valeur par défaut utilisée", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
 // GetDBConnectionString retourne la chaîne de connexion PostgreSQL
 func (c *Config) GetDBConnectionString() string {
 	// Si DATABASE_URL est défini (Scalingo), l'utiliser directement
 	if c.DatabaseURL != "" {
-		log.Println("✅ Utilisation d'une URL PostgreSQL fournie par l'environnement")
+		slog.Debug("Utilisation d'une URL PostgreSQL fournie par l'environnement")
 		// Scalingo supporte : "require", "verify-full", "verify-ca", "disable"
 		// mais pas "prefer", donc on remplace
 		connStr := strings.ReplaceAll(c.DatabaseURL, "sslmode=prefer", "sslmode=require")
@@ -0,0 +1,94 @@
+package cors
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Méthodes et en-têtes acceptés en requête cross-origin
+const (
+	allowedMethods = "GET, HEAD, POST, DELETE, OPTIONS"
+	allowedHeaders = "Authorization, Content-Type, Range, If-Range"
+	exposedHeaders = "Content-Length, Content-Range, Accept-Ranges, Retry-After"
+	maxAge         = "600" // durée de cache des preflight par le navigateur (secondes)
+)
+
+// Policy applique les règles CORS de ALLOWED_ORIGINS aux routes /api/.
+//
+// Avec une liste d'origines ("https://a.fr,https://b.fr"), seule l'origine
+// de la requête est renvoyée si elle figure dans la liste, avec
+// Access-Control-Allow-Credentials : le cookie de session est alors envoyé.
+// Avec "*", toute origine est acceptée mais sans identifiants : un site tiers
+// ne peut pas lire les données d'un utilisateur connecté.
+type Policy struct {
+	any     bool
+	origins map[string]bool
+}
+
+// New lit une liste d'origines séparées par des virgules (ex: ALLOWED_ORIGINS)
+func New(allowed string) *Policy {
+	p := &Policy{origins: make(map[string]bool)}
+	for _, o := range strings.Split(allowed, ",") {
+		o = normalize(o)
+		switch o {
+		case "":
+		case "*":
+			p.any = true
+		default:
+			p.origins[o] = true
+		}
+	}
+	return p
+}
+
+// Allows indique si origin peut appeler l'API
+func (p *Policy) Allows(origin string) bool {
+	return p.any || p.origins[normalize(origin)]
+}
+
+// Middleware ajoute les en-têtes CORS aux réponses des routes /api/ et répond
+// lui-même aux requêtes preflight (OPTIONS), sans les transmettre aux handlers
+func (p *Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && p.Allows(origin)
+		if allowed {
+			if p.origins[normalize(origin)] {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			} else {
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if allowed {
+				h.Set("Access-Control-Allow-Methods", allowedMethods)
+				h.Set("Access-Control-Allow-Headers", allowedHeaders)
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			// Sans en-têtes Allow-*, le navigateur bloque la requête réelle
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed {
+			h.Set("Access-Control-Expose-Headers", exposedHeaders)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// normalize compare les origines sans tenir compte de la casse ni d'un "/" final
+func normalize(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	nom VARCHAR(100) NOT NULL,
+	prenom VARCHAR(100) NOT NULL,
+	sexe VARCHAR(10),
+	password_hash VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
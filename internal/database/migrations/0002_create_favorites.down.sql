@@ -0,0 +1,2 @@
+-- favorites_legacy est conservée : c'est la seule copie des anciens favoris globaux
+DROP TABLE IF EXISTS favorites;
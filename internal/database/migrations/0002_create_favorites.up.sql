@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS favorites (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+	artist_id INTEGER NOT NULL,
+	artist_name VARCHAR(255) NOT NULL,
+	artist_image VARCHAR(512),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Anciennes bases : favoris globaux (UNIQUE(artist_id)) -> favoris par utilisateur
+ALTER TABLE favorites ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE favorites DROP CONSTRAINT IF EXISTS favorites_artist_id_key;
+DROP INDEX IF EXISTS idx_favorites_artist_id;
+
+-- Tables créées par l'ancien AutoMigrate GORM : created_at en secondes Unix (BIGINT)
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'favorites' AND column_name = 'created_at' AND data_type = 'bigint'
+	) THEN
+		ALTER TABLE favorites
+			ALTER COLUMN created_at TYPE TIMESTAMP USING to_timestamp(created_at),
+			ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+	END IF;
+END $$;
+
+-- Les anciens favoris globaux n'appartiennent à personne et resteraient
+-- invisibles : ils sont archivés dans favorites_legacy, en attente d'une
+-- attribution à un compte ou d'un nettoyage explicite (voir DATABASE_SETUP.md).
+-- user_id reste nullable jusque-là.
+CREATE TABLE IF NOT EXISTS favorites_legacy (
+	id INTEGER PRIMARY KEY,
+	artist_id INTEGER NOT NULL,
+	artist_name VARCHAR(255) NOT NULL,
+	artist_image VARCHAR(512),
+	created_at TIMESTAMP,
+	archived_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO favorites_legacy (id, artist_id, artist_name, artist_image, created_at)
+SELECT id, artist_id, artist_name, artist_image, created_at
+FROM favorites WHERE user_id IS NULL
+ON CONFLICT (id) DO NOTHING;
+DELETE FROM favorites WHERE user_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_favorites_user_artist ON favorites(user_id, artist_id);
+CREATE INDEX IF NOT EXISTS idx_artist_id ON favorites(artist_id);
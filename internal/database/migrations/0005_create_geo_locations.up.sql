@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS geo_locations (
+	slug VARCHAR(255) PRIMARY KEY,
+	city VARCHAR(255) NOT NULL,
+	country VARCHAR(255) NOT NULL,
+	lat DOUBLE PRECISION NOT NULL DEFAULT 0,
+	lon DOUBLE PRECISION NOT NULL DEFAULT 0,
+	display_name TEXT,
+	found BOOLEAN NOT NULL DEFAULT FALSE,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS artist_previews (
+	artist_id INTEGER PRIMARY KEY,
+	artist_name VARCHAR(255) NOT NULL,
+	found BOOLEAN NOT NULL DEFAULT FALSE,
+	provider VARCHAR(32),
+	track_artist VARCHAR(255) NOT NULL DEFAULT '',
+	track_title VARCHAR(512),
+	album VARCHAR(512),
+	artwork_url TEXT,
+	preview_url TEXT,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
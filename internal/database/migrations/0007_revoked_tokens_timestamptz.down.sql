@@ -0,0 +1,3 @@
+ALTER TABLE revoked_tokens
+	ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN revoked_at TYPE TIMESTAMP USING revoked_at AT TIME ZONE current_setting('TimeZone');
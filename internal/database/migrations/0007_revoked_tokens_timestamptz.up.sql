@@ -0,0 +1,7 @@
+-- expires_at est comparé à NOW() : un TIMESTAMP sans fuseau rempli avec
+-- l'heure locale du serveur Go purgeait les révocations trop tôt (ou trop tard)
+-- dès que ce fuseau différait de celui de la session PostgreSQL.
+-- Les valeurs existantes sont interprétées dans le fuseau de la session.
+ALTER TABLE revoked_tokens
+	ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN revoked_at TYPE TIMESTAMPTZ USING revoked_at AT TIME ZONE current_setting('TimeZone');
@@ -0,0 +1,5 @@
+-- updated_at est écrit depuis Go (time.Now) et sert au délai avant de retenter
+-- un lieu introuvable : sans fuseau, il dépendait de celui de la session PostgreSQL.
+-- Les valeurs existantes sont interprétées dans le fuseau de la session.
+ALTER TABLE geo_locations
+	ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE current_setting('TimeZone');
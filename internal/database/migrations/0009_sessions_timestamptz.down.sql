@@ -0,0 +1,4 @@
+ALTER TABLE sessions
+	ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN revoked_at TYPE TIMESTAMP USING revoked_at AT TIME ZONE current_setting('TimeZone');
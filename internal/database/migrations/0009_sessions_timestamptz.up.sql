@@ -0,0 +1,8 @@
+-- expires_at est comparé à NOW() : un TIMESTAMP sans fuseau rempli avec
+-- l'heure locale du serveur Go expirait les sessions trop tôt (ou trop tard)
+-- dès que ce fuseau différait de celui de la session PostgreSQL.
+-- Les valeurs existantes sont interprétées dans le fuseau de la session.
+ALTER TABLE sessions
+	ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE current_setting('TimeZone'),
+	ALTER COLUMN revoked_at TYPE TIMESTAMPTZ USING revoked_at AT TIME ZONE current_setting('TimeZone');
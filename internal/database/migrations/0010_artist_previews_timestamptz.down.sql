@@ -0,0 +1,2 @@
+ALTER TABLE artist_previews
+	ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE current_setting('TimeZone');
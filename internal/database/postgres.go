@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"strings"
+	"log/slog"
+	"time"
 
 	"groupiepersso/internal/core"
+	"groupiepersso/internal/logging"
 
 	_ "github.com/lib/pq"
 )
@@ -14,83 +16,74 @@ import (
 var DB *sql.DB
 
 // InitDB initialise la connexion à la base de données PostgreSQL
+// et applique les migrations en attente
 func InitDB() error {
-	log.Println("🔄 InitDB() démarrage...")
-	
 	cfg := core.LoadConfig()
-	log.Printf("📋 cfg.DatabaseURL: %v", cfg.DatabaseURL)
-	
-	if err := cfg.ParseDatabaseURL(); err != nil {
-		return fmt.Errorf("erreur parsing DATABASE_URL: %v", err)
-	}
 
-	connStr := cfg.GetDBConnectionString()
-	log.Printf("🔐 Connection string: %s", maskPassword(connStr))
-
-	var err error
-	DB, err = sql.Open("postgres", connStr)
+	db, err := Open(cfg)
 	if err != nil {
-		log.Printf("❌ Erreur sql.Open(): %v", err)
-		return fmt.Errorf("erreur lors de l'ouverture de la connexion: %v", err)
+		return err
 	}
-	log.Println("✅ sql.Open() réussi")
+	slog.Info("Connexion à PostgreSQL établie")
 
-	// Vérification de la connexion
-	if err = DB.Ping(); err != nil {
-		log.Printf("❌ Erreur DB.Ping(): %v", err)
-		return fmt.Errorf("erreur lors du ping de la base de données: %v", err)
+	// Mise à jour du schéma (idempotent, protégé par un verrou consultatif).
+	// DB n'est exposé qu'une fois le schéma à jour : un échec laisse DB à nil
+	// et le serveur continue sans persistance.
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("erreur lecture des migrations: %v", err)
 	}
-	log.Println("✅ DB.Ping() réussi")
-
-	log.Println("✅ Connexion à PostgreSQL établie avec succès")
-
-	// Création de la table des favoris si elle n'existe pas
-	if err := createTables(); err != nil {
-		return fmt.Errorf("erreur lors de la création des tables: %v", err)
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("erreur lors des migrations: %v", err)
 	}
-
+	slog.Info("Schéma à jour", "applied", len(applied))
+	DB = db
 	return nil
 }
 
-// createTables crée les tables nécessaires si elles n'existent pas
-func createTables() error {
-	if DB == nil {
-		return fmt.Errorf("connexion à la base de données non initialisée")
+// InitDBRetry appelle InitDB jusqu'à attempts fois, en doublant l'attente
+// entre deux essais (PostgreSQL démarré en même temps que l'application)
+func InitDBRetry(attempts int, delay time.Duration) error {
+	var err error
+	for i := 1; ; i++ {
+		if err = InitDB(); err == nil || i >= attempts {
+			return err
+		}
+		slog.Warn("Base de données indisponible, nouvel essai", "attempt", i, "retry_in", delay, "err", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Open ouvre et vérifie une connexion PostgreSQL à partir de la configuration
+func Open(cfg *core.Config) (*sql.DB, error) {
+	if err := cfg.ParseDatabaseURL(); err != nil {
+		return nil, fmt.Errorf("erreur parsing DATABASE_URL: %v", err)
 	}
 
-	query := `
-	CREATE TABLE IF NOT EXISTS favorites (
-		id SERIAL PRIMARY KEY,
-		artist_id INTEGER NOT NULL,
-		artist_name VARCHAR(255) NOT NULL,
-		artist_image VARCHAR(512),
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		UNIQUE(artist_id)
-	);
-	
-	CREATE INDEX IF NOT EXISTS idx_artist_id ON favorites(artist_id);
-	`
+	connStr := cfg.GetDBConnectionString()
+	slog.Debug("Connexion PostgreSQL", "dsn", logging.Redact(connStr))
 
-	_, err := DB.Exec(query)
+	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		return fmt.Errorf("erreur lors de la création de la table favorites: %v", err)
+		return nil, fmt.Errorf("erreur lors de l'ouverture de la connexion: %v", err)
 	}
 
-	log.Println("✅ Table 'favorites' créée ou vérifiée avec succès")
-	log.Println("✅ InitDB() complété avec succès")
-	return nil
-}
-
-// maskPassword masque le mot de passe dans une connection string pour la sécurité des logs
-func maskPassword(connStr string) string {
-	// Simple masking: remplace le mot de passe par ****
-	return strings.ReplaceAll(connStr, "password=", "password=****")
+	// Vérification de la connexion : ne pas retourner une connexion inutilisable
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erreur lors du ping de la base de données: %v", err)
+	}
+	return db, nil
 }
 
 // CloseDB ferme la connexion à la base de données
 func CloseDB() {
 	if DB != nil {
 		DB.Close()
-		log.Println("Connexion à la base de données fermée")
+		slog.Info("Connexion à la base de données fermée")
 	}
 }
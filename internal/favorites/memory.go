@@ -0,0 +1,112 @@
+package favorites
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/models"
+)
+
+// MemoryStore garde les favoris en mémoire : utilisé sans base de données
+// et pour tester l'API des favoris
+type MemoryStore struct {
+	mu     sync.Mutex
+	nextID int
+	byUser map[int]map[int]models.Favorite // userID -> artistID -> favori
+}
+
+// NewMemoryStore crée un store vide
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1, byUser: make(map[int]map[int]models.Favorite)}
+}
+
+// List retourne les favoris de l'utilisateur
+func (s *MemoryStore) List(ctx context.Context, userID int) ([]models.Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	favorites := make([]models.Favorite, 0, len(s.byUser[userID]))
+	for _, fav := range s.byUser[userID] {
+		favorites = append(favorites, fav)
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		if !favorites[i].CreatedAt.Equal(favorites[j].CreatedAt) {
+			return favorites[i].CreatedAt.After(favorites[j].CreatedAt)
+		}
+		return favorites[i].ID > favorites[j].ID
+	})
+	return favorites, nil
+}
+
+// Add ajoute un favori
+func (s *MemoryStore) Add(ctx context.Context, userID int, fav models.Favorite) (models.Favorite, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.byUser[userID]
+	if user == nil {
+		user = make(map[int]models.Favorite)
+		s.byUser[userID] = user
+	}
+	if existing, ok := user[fav.ArtistID]; ok {
+		return existing, false, nil
+	}
+
+	fav.ID = s.nextID
+	fav.CreatedAt = time.Now()
+	s.nextID++
+	user[fav.ArtistID] = fav
+	return fav, true, nil
+}
+
+// Remove supprime un artiste des favoris
+func (s *MemoryStore) Remove(ctx context.Context, userID, artistID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byUser[userID][artistID]; !ok {
+		return false, nil
+	}
+	delete(s.byUser[userID], artistID)
+	return true, nil
+}
+
+// RemoveByID supprime un favori par son identifiant
+func (s *MemoryStore) RemoveByID(ctx context.Context, userID, id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for artistID, fav := range s.byUser[userID] {
+		if fav.ID == id {
+			delete(s.byUser[userID], artistID)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Exists indique si l'artiste est dans les favoris
+func (s *MemoryStore) Exists(ctx context.Context, userID, artistID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.byUser[userID][artistID]
+	return ok, nil
+}
+
+// Stats compte les favoris et les utilisateurs qui en ont
+func (s *MemoryStore) Stats(ctx context.Context) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var st Stats
+	for _, favs := range s.byUser {
+		if len(favs) > 0 {
+			st.Favorites += len(favs)
+			st.Users++
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,104 @@
+package favorites
+
+import (
+	"context"
+	"testing"
+
+	"groupiepersso/internal/models"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	add := func(userID, artistID int) func() (bool, error) {
+		return func() (bool, error) {
+			_, created, err := s.Add(ctx, userID, models.Favorite{ArtistID: artistID, ArtistName: "Queen"})
+			return created, err
+		}
+	}
+	remove := func(userID, artistID int) func() (bool, error) {
+		return func() (bool, error) { return s.Remove(ctx, userID, artistID) }
+	}
+	exists := func(userID, artistID int) func() (bool, error) {
+		return func() (bool, error) { return s.Exists(ctx, userID, artistID) }
+	}
+
+	// Les étapes s'enchaînent sur le même store
+	steps := []struct {
+		name string
+		run  func() (bool, error)
+		want bool
+	}{
+		{"ajout", add(1, 10), true},
+		{"ajout d'un autre artiste", add(1, 20), true},
+		{"ajout en double", add(1, 10), false},
+		{"même artiste pour un autre utilisateur", add(2, 10), true},
+		{"favori présent", exists(1, 10), true},
+		{"favori absent", exists(1, 30), false},
+		{"retrait", remove(1, 20), true},
+		{"retrait d'un favori absent", remove(1, 20), false},
+		{"favori retiré", exists(1, 20), false},
+		{"favori de l'autre utilisateur conservé", exists(2, 10), true},
+	}
+	for _, step := range steps {
+		got, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: erreur inattendue: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: obtenu %v, attendu %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestMemoryStoreAddTwiceReturnsExisting(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	first, _, err := s.Add(ctx, 1, models.Favorite{ArtistID: 10, ArtistName: "Queen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, created, err := s.Add(ctx, 1, models.Favorite{ArtistID: 10, ArtistName: "Autre nom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || again.ID != first.ID || again.ArtistName != "Queen" {
+		t.Errorf("second ajout: obtenu %+v (created=%v), attendu le favori existant %+v", again, created, first)
+	}
+}
+
+func TestMemoryStoreListOrder(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	for _, artistID := range []int{10, 20, 30} {
+		if _, _, err := s.Add(ctx, 1, models.Favorite{ArtistID: artistID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := s.Add(ctx, 2, models.Favorite{ArtistID: 40}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.List(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Du plus récent au plus ancien, sans les favoris des autres utilisateurs
+	want := []int{30, 20, 10}
+	if len(list) != len(want) {
+		t.Fatalf("List: %d favoris, attendu %d", len(list), len(want))
+	}
+	for i, fav := range list {
+		if fav.ArtistID != want[i] {
+			t.Errorf("List[%d]: artiste %d, attendu %d", i, fav.ArtistID, want[i])
+		}
+	}
+
+	empty, err := s.List(ctx, 3)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("List sans favori: obtenu %v (err %v), attendu une liste vide non nil", empty, err)
+	}
+}
@@ -0,0 +1,117 @@
+package favorites
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"groupiepersso/internal/models"
+)
+
+// PostgresStore stocke les favoris dans la table favorites
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crée un store adossé à db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// List retourne les favoris de l'utilisateur
+func (s *PostgresStore) List(ctx context.Context, userID int) ([]models.Favorite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, artist_id, artist_name, artist_image, created_at
+		FROM favorites
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := []models.Favorite{}
+	for rows.Next() {
+		var fav models.Favorite
+		var artistImage sql.NullString
+		var createdAt sql.NullTime
+		if err := rows.Scan(&fav.ID, &fav.ArtistID, &fav.ArtistName, &artistImage, &createdAt); err != nil {
+			return nil, err
+		}
+		fav.ArtistImage = artistImage.String
+		if createdAt.Valid {
+			fav.CreatedAt = createdAt.Time
+		}
+		favorites = append(favorites, fav)
+	}
+	return favorites, rows.Err()
+}
+
+// Add ajoute un favori (INSERT ... ON CONFLICT DO NOTHING)
+func (s *PostgresStore) Add(ctx context.Context, userID int, fav models.Favorite) (models.Favorite, bool, error) {
+	var createdAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO favorites (user_id, artist_id, artist_name, artist_image)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, artist_id) DO NOTHING
+		RETURNING id, created_at
+	`, userID, fav.ArtistID, fav.ArtistName, fav.ArtistImage).Scan(&fav.ID, &createdAt)
+
+	created := true
+	if errors.Is(err, sql.ErrNoRows) {
+		// Aucune ligne retournée : l'artiste est déjà en favori
+		created = false
+		var artistImage sql.NullString
+		err = s.db.QueryRowContext(ctx, `
+			SELECT id, artist_name, artist_image, created_at
+			FROM favorites WHERE user_id = $1 AND artist_id = $2
+		`, userID, fav.ArtistID).Scan(&fav.ID, &fav.ArtistName, &artistImage, &createdAt)
+		fav.ArtistImage = artistImage.String
+	}
+	if err != nil {
+		return models.Favorite{}, false, err
+	}
+
+	if createdAt.Valid {
+		fav.CreatedAt = createdAt.Time
+	}
+	return fav, created, nil
+}
+
+// Remove supprime un artiste des favoris
+func (s *PostgresStore) Remove(ctx context.Context, userID, artistID int) (bool, error) {
+	return s.delete(ctx, `DELETE FROM favorites WHERE user_id = $1 AND artist_id = $2`, userID, artistID)
+}
+
+// RemoveByID supprime un favori par son identifiant
+func (s *PostgresStore) RemoveByID(ctx context.Context, userID, id int) (bool, error) {
+	return s.delete(ctx, `DELETE FROM favorites WHERE user_id = $1 AND id = $2`, userID, id)
+}
+
+// Exists indique si l'artiste est dans les favoris
+func (s *PostgresStore) Exists(ctx context.Context, userID, artistID int) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM favorites WHERE user_id = $1 AND artist_id = $2)
+	`, userID, artistID).Scan(&exists)
+	return exists, err
+}
+
+// Stats compte les favoris et les utilisateurs qui en ont
+func (s *PostgresStore) Stats(ctx context.Context) (Stats, error) {
+	var st Stats
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT user_id) FROM favorites
+	`).Scan(&st.Favorites, &st.Users)
+	return st, err
+}
+
+func (s *PostgresStore) delete(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
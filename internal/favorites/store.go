@@ -0,0 +1,39 @@
+package favorites
+
+import (
+	"context"
+
+	"groupiepersso/internal/models"
+)
+
+// Store donne accès aux favoris d'un utilisateur, quelle que soit la persistance
+type Store interface {
+	// List retourne les favoris de l'utilisateur, du plus récent au plus ancien
+	List(ctx context.Context, userID int) ([]models.Favorite, error)
+	// Add ajoute un favori ; s'il existe déjà, le favori existant est retourné avec created == false
+	Add(ctx context.Context, userID int, fav models.Favorite) (saved models.Favorite, created bool, err error)
+	// Remove supprime un artiste des favoris ; false s'il n'y était pas
+	Remove(ctx context.Context, userID, artistID int) (bool, error)
+	// RemoveByID supprime un favori par son identifiant ; false s'il n'existe pas
+	RemoveByID(ctx context.Context, userID, id int) (bool, error)
+	// Exists indique si l'artiste est dans les favoris
+	Exists(ctx context.Context, userID, artistID int) (bool, error)
+	// Stats compte les favoris de tous les utilisateurs (métriques)
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Stats résume le contenu d'un store
+type Stats struct {
+	Favorites int // nombre total de favoris
+	Users     int // utilisateurs ayant au moins un favori
+}
+
+// Merge ajoute plusieurs favoris à un utilisateur (doublons ignorés)
+func Merge(ctx context.Context, s Store, userID int, favs []models.Favorite) error {
+	for _, fav := range favs {
+		if _, _, err := s.Add(ctx, userID, fav); err != nil {
+			return err
+		}
+	}
+	return nil
+}
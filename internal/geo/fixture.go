@@ -0,0 +1,50 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Fixture est un géocodeur hors-ligne qui lit les coordonnées dans un fichier JSON :
+//
+//	{"london-uk": {"lat": 51.5073, "lon": -0.1276}, ...}
+//
+// Utile en développement sans réseau et dans les tests.
+type Fixture struct {
+	points map[string]Point
+}
+
+// NewFixture crée un géocodeur à partir d'une table en mémoire
+func NewFixture(points map[string]Point) *Fixture {
+	return &Fixture{points: points}
+}
+
+// LoadFixture lit un fichier de coordonnées
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("geo: lecture fixtures: %w", err)
+	}
+	return ParseFixture(data)
+}
+
+// ParseFixture décode des coordonnées au format de LoadFixture
+// (ex: fixtures embarquées dans le binaire)
+func ParseFixture(data []byte) (*Fixture, error) {
+	points := make(map[string]Point)
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("geo: décodage fixtures: %w", err)
+	}
+	return NewFixture(points), nil
+}
+
+// Geocode retourne le point associé au lieu, ou ErrNotFound
+func (f *Fixture) Geocode(ctx context.Context, q Query) (Point, error) {
+	p, ok := f.points[q.Slug]
+	if !ok {
+		return Point{}, ErrNotFound
+	}
+	return p, nil
+}
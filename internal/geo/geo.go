@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"groupiepersso/internal/groupie"
+)
+
+// ErrNotFound est retourné par un Geocoder quand le lieu est introuvable
+var ErrNotFound = errors.New("geo: lieu introuvable")
+
+// Query décrit un lieu à géocoder, tel que fourni par l'API Groupie Trackers
+type Query struct {
+	Slug    string // ex: "north_carolina-usa"
+	City    string // ex: "north carolina"
+	Country string // ex: "usa"
+}
+
+// NewQuery construit une requête à partir d'un lieu de l'API
+func NewQuery(slug string) Query {
+	city, country := groupie.ParseLocation(slug)
+	return Query{Slug: slug, City: city, Country: country}
+}
+
+// Point est une coordonnée géographique
+type Point struct {
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	DisplayName string  `json:"display_name,omitempty"`
+}
+
+// Geocoder convertit un lieu en coordonnées
+type Geocoder interface {
+	Geocode(ctx context.Context, q Query) (Point, error)
+}
+
+// Location est un lieu géocodé (ou marqué introuvable) conservé en cache
+type Location struct {
+	Slug        string    `json:"location"`
+	City        string    `json:"city"`
+	Country     string    `json:"country"`
+	Lat         float64   `json:"lat"`
+	Lon         float64   `json:"lon"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Found       bool      `json:"found"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,99 @@
+package geo
+
+import (
+	"sort"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/groupie"
+)
+
+// FeatureCollection est un document GeoJSON (RFC 7946)
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature est un objet GeoJSON avec sa géométrie et ses propriétés
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry est une géométrie GeoJSON (Point ou LineString)
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// concert est une date de concert dans un lieu géocodé
+type concert struct {
+	loc  Location
+	date string // date ISO "2006-01-02"
+}
+
+// Tour construit la tournée d'un artiste : un Point par lieu de concert (avec
+// ses dates) et une LineString reliant les concerts dans l'ordre chronologique.
+// Les lieux absents de lookup (pas encore géocodés) sont ignorés.
+func Tour(e *catalog.Entry, lookup func(slug string) (Location, bool)) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+
+	slugs := make([]string, 0, len(e.Relations))
+	for slug := range e.Relations {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	var concerts []concert
+	for _, slug := range slugs {
+		loc, ok := lookup(slug)
+		if !ok || !loc.Found {
+			continue
+		}
+
+		dates := []string{}
+		for _, d := range e.Relations[slug] {
+			t, err := groupie.ParseDate(d)
+			if err != nil {
+				continue
+			}
+			iso := t.Format("2006-01-02")
+			dates = append(dates, iso)
+			concerts = append(concerts, concert{loc: loc, date: iso})
+		}
+		sort.Strings(dates)
+
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: Geometry{Type: "Point", Coordinates: []float64{loc.Lon, loc.Lat}},
+			Properties: map[string]interface{}{
+				"artist_id": e.Artist.ID,
+				"artist":    e.Artist.Name,
+				"location":  slug,
+				"city":      loc.City,
+				"country":   loc.Country,
+				"dates":     dates,
+			},
+		})
+	}
+
+	sort.SliceStable(concerts, func(i, j int) bool { return concerts[i].date < concerts[j].date })
+	if len(concerts) >= 2 {
+		coords := make([][]float64, 0, len(concerts))
+		for _, c := range concerts {
+			coords = append(coords, []float64{c.loc.Lon, c.loc.Lat})
+		}
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: Geometry{Type: "LineString", Coordinates: coords},
+			Properties: map[string]interface{}{
+				"artist_id": e.Artist.ID,
+				"artist":    e.Artist.Name,
+				"route":     true,
+				"start":     concerts[0].date,
+				"end":       concerts[len(concerts)-1].date,
+			},
+		})
+	}
+	return fc
+}
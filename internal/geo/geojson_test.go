@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"context"
+	"testing"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/groupie"
+)
+
+func TestTour(t *testing.T) {
+	s := NewService(NewFixture(map[string]Point{
+		"london-uk":      {Lat: 51.5073, Lon: -0.1276},
+		"paris-france":   {Lat: 48.8566, Lon: 2.3522},
+		"berlin-germany": {Lat: 52.52, Lon: 13.405},
+	}), NewMemoryStore())
+	e := &catalog.Entry{
+		Artist: groupie.Artist{ID: 1, Name: "Queen"},
+		Relations: map[string][]string{
+			"paris-france":   {"14-12-1973"},
+			"london-uk":      {"02-01-1974", "*01-12-1973"},
+			"berlin-germany": {"05-05-1980"},
+			"atlantis-sea":   {"01-01-1975"},
+		},
+	}
+	s.Warm(context.Background(), []string{"london-uk", "paris-france", "atlantis-sea"})
+
+	fc := Tour(e, s.Get)
+	if fc.Type != "FeatureCollection" {
+		t.Fatalf("Type = %q", fc.Type)
+	}
+	// Berlin n'est pas encore géocodé et Atlantis est introuvable :
+	// deux points (triés par lieu) et la ligne de la tournée
+	if len(fc.Features) != 3 {
+		t.Fatalf("%d features, attendu 3", len(fc.Features))
+	}
+
+	london := fc.Features[0]
+	if london.Geometry.Type != "Point" || london.Properties["location"] != "london-uk" {
+		t.Fatalf("premier point = %+v", london)
+	}
+	if c := london.Geometry.Coordinates.([]float64); c[0] != -0.1276 || c[1] != 51.5073 {
+		t.Errorf("coordonnées de london-uk = %v, attendu [lon, lat]", c)
+	}
+	if dates := london.Properties["dates"].([]string); len(dates) != 2 || dates[0] != "1973-12-01" {
+		t.Errorf("dates de london-uk = %v", dates)
+	}
+
+	route := fc.Features[2]
+	if route.Geometry.Type != "LineString" {
+		t.Fatalf("dernière feature = %+v, attendu la LineString", route)
+	}
+	// Ordre chronologique : Londres (1973-12-01), Paris (1973-12-14), Londres (1974-01-02)
+	if coords := route.Geometry.Coordinates.([][]float64); len(coords) != 3 || coords[1][0] != 2.3522 {
+		t.Errorf("tracé = %v", coords)
+	}
+	if route.Properties["start"] != "1973-12-01" || route.Properties["end"] != "1974-01-02" {
+		t.Errorf("start/end = %v/%v", route.Properties["start"], route.Properties["end"])
+	}
+}
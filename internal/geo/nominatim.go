@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+)
+
+// DefaultNominatimURL est l'instance publique de Nominatim
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// Nominatim géocode via l'API de recherche OpenStreetMap.
+// La politique d'usage impose un User-Agent identifiant l'application
+// et au plus une requête par seconde (voir RateLimit).
+type Nominatim struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// NewNominatim crée un géocodeur Nominatim
+func NewNominatim(baseURL, userAgent string) *Nominatim {
+	if baseURL == "" {
+		baseURL = DefaultNominatimURL
+	}
+	return &Nominatim{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		userAgent:  userAgent,
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("nominatim", logging.Transport(nil))},
+	}
+}
+
+// Geocode interroge /search avec "ville, pays"
+func (n *Nominatim) Geocode(ctx context.Context, q Query) (Point, error) {
+	params := url.Values{}
+	params.Set("format", "json")
+	params.Set("limit", "1")
+	params.Set("q", strings.TrimSpace(q.City+", "+q.Country))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return Point{}, err
+	}
+	req.Header.Set("User-Agent", n.userAgent)
+	req.Header.Set("Accept-Language", "fr")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return Point{}, fmt.Errorf("geo: appel nominatim: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Point{}, fmt.Errorf("geo: nominatim a répondu %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+		DisplayName string `json:"display_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Point{}, fmt.Errorf("geo: décodage nominatim: %w", err)
+	}
+	if len(results) == 0 {
+		return Point{}, ErrNotFound
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("geo: latitude invalide %q", results[0].Lat)
+	}
+	lon, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("geo: longitude invalide %q", results[0].Lon)
+	}
+	return Point{Lat: lat, Lon: lon, DisplayName: results[0].DisplayName}, nil
+}
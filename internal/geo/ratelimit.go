@@ -0,0 +1,39 @@
+package geo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimited espace les appels au géocodeur sous-jacent d'au moins interval
+type RateLimited struct {
+	next     Geocoder
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// RateLimit enveloppe g pour ne pas dépasser un appel par interval
+func RateLimit(g Geocoder, interval time.Duration) *RateLimited {
+	return &RateLimited{next: g, interval: interval}
+}
+
+// Geocode attend son tour (ou l'annulation de ctx) puis délègue
+func (r *RateLimited) Geocode(ctx context.Context, q Query) (Point, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if wait := r.interval - time.Since(r.last); wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return Point{}, ctx.Err()
+		case <-t.C:
+		}
+	}
+	r.last = time.Now()
+	return r.next.Geocode(ctx, q)
+}
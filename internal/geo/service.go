@@ -0,0 +1,207 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/logging"
+)
+
+// notFoundTTL est le délai avant de retenter un lieu marqué introuvable
+const notFoundTTL = 7 * 24 * time.Hour
+
+// watchInterval est la fréquence de vérification des nouveaux lieux du catalogue
+const watchInterval = time.Minute
+
+// loadInterval est la fréquence de vérification tant que le catalogue n'est pas chargé
+const loadInterval = 5 * time.Second
+
+// maxRetryDelay plafonne l'attente avant de retenter un lieu en échec
+const maxRetryDelay = time.Hour
+
+// Service géocode les lieux de concerts une seule fois et garde le résultat
+// en mémoire et dans le Store (PostgreSQL en production)
+type Service struct {
+	geocoder Geocoder
+	store    Store
+
+	mu       sync.RWMutex
+	cache    map[string]Location
+	failures map[string]failure
+}
+
+// failure mémorise les échecs consécutifs de géocodage d'un lieu (réseau,
+// quota...) pour espacer les tentatives suivantes
+type failure struct {
+	count int
+	next  time.Time
+}
+
+// NewService crée un service de géocodage
+func NewService(g Geocoder, store Store) *Service {
+	return &Service{geocoder: g, store: store, cache: make(map[string]Location), failures: make(map[string]failure)}
+}
+
+// Load charge en mémoire les lieux déjà présents dans le store
+func (s *Service) Load(ctx context.Context) error {
+	locations, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, loc := range locations {
+		s.cache[loc.Slug] = loc
+	}
+	return nil
+}
+
+// Get retourne un lieu depuis le cache, sans appel au géocodeur
+func (s *Service) Get(slug string) (Location, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	loc, ok := s.cache[slug]
+	return loc, ok
+}
+
+// Locations retourne les lieux géocodés parmi slugs, et le nombre de lieux
+// encore en attente de géocodage
+func (s *Service) Locations(slugs []string) (found []Location, pending int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found = []Location{}
+	for _, slug := range slugs {
+		loc, ok := s.cache[slug]
+		switch {
+		case !ok:
+			pending++
+		case loc.Found:
+			found = append(found, loc)
+		}
+	}
+	return found, pending
+}
+
+// Lookup retourne un lieu, en le géocodant et en le persistant si nécessaire
+func (s *Service) Lookup(ctx context.Context, slug string) (Location, error) {
+	if loc, ok := s.Get(slug); ok && !needsRetry(loc) {
+		return loc, nil
+	}
+
+	q := NewQuery(slug)
+	loc := Location{Slug: slug, City: q.City, Country: q.Country, UpdatedAt: time.Now()}
+
+	p, err := s.geocoder.Geocode(ctx, q)
+	switch {
+	case err == nil:
+		loc.Lat, loc.Lon, loc.DisplayName, loc.Found = p.Lat, p.Lon, p.DisplayName, true
+	case errors.Is(err, ErrNotFound):
+		loc.Found = false
+	default:
+		return Location{}, err
+	}
+
+	if err := s.store.Put(ctx, loc); err != nil {
+		logging.FromContext(ctx).Warn("Erreur sauvegarde lieu", "slug", slug, "err", err)
+	}
+	s.mu.Lock()
+	s.cache[slug] = loc
+	s.mu.Unlock()
+	return loc, nil
+}
+
+// Warm géocode tous les lieux absents du cache et retourne le nombre de lieux
+// restant à traiter : échecs, et lieux dont le délai avant nouvelle tentative
+// (exponentiel à partir de watchInterval) n'est pas écoulé
+func (s *Service) Warm(ctx context.Context, slugs []string) (failed int) {
+	done := 0
+	for _, slug := range slugs {
+		if loc, ok := s.Get(slug); ok && !needsRetry(loc) {
+			continue
+		}
+		if s.backingOff(slug) {
+			failed++
+			continue
+		}
+		if _, err := s.Lookup(ctx, slug); err != nil {
+			failed++
+			if ctx.Err() != nil {
+				return failed
+			}
+			s.fail(slug)
+			logging.FromContext(ctx).Warn("Géocodage échoué", "slug", slug, "err", err)
+			continue
+		}
+		s.mu.Lock()
+		delete(s.failures, slug)
+		s.mu.Unlock()
+		done++
+	}
+	if done > 0 {
+		logging.FromContext(ctx).Info("Lieux géocodés", "count", done)
+	}
+	return failed
+}
+
+// Run géocode les lieux de chaque nouvelle version du catalogue, jusqu'à
+// l'annulation de ctx ; le cache est chargé au préalable par Load
+func (s *Service) Run(ctx context.Context, cat *catalog.Catalog) {
+	var version uint64
+	for {
+		// La version n'est retenue que si tous les lieux ont été traités,
+		// sinon les échecs sont retentés une fois leur délai écoulé
+		snap := cat.Snapshot()
+		if snap != nil && snap.Version != version {
+			if s.Warm(ctx, snap.LocationNames()) == 0 {
+				version = snap.Version
+			}
+		}
+
+		// Tant que le catalogue n'est pas chargé, vérifier plus souvent
+		wait := watchInterval
+		if snap == nil {
+			wait = loadInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backingOff indique si le dernier échec de slug est trop récent pour retenter
+func (s *Service) backingOff(slug string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.failures[slug]
+	return ok && time.Now().Before(f.next)
+}
+
+// fail enregistre un échec de slug et double le délai avant la prochaine tentative
+func (s *Service) fail(slug string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.failures[slug]
+	f.count++
+	f.next = time.Now().Add(retryDelay(f.count))
+	s.failures[slug] = f
+}
+
+// retryDelay retourne l'attente après n échecs consécutifs :
+// watchInterval, puis le double à chaque échec, plafonné à maxRetryDelay
+func retryDelay(n int) time.Duration {
+	d := watchInterval
+	for i := 1; i < n && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
+
+// needsRetry indique si un lieu introuvable doit être retenté
+func needsRetry(loc Location) bool {
+	return !loc.Found && time.Since(loc.UpdatedAt) > notFoundTTL
+}
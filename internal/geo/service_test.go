@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flaky est un géocodeur qui échoue toujours et compte ses appels
+type flaky struct {
+	calls int
+}
+
+func (f *flaky) Geocode(ctx context.Context, q Query) (Point, error) {
+	f.calls++
+	return Point{}, errors.New("quota dépassé")
+}
+
+func TestServiceWarm(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	s := NewService(NewFixture(map[string]Point{
+		"london-uk": {Lat: 51.5073, Lon: -0.1276},
+	}), store)
+
+	if failed := s.Warm(ctx, []string{"london-uk", "atlantis-sea"}); failed != 0 {
+		t.Fatalf("Warm: %d échecs, attendu 0", failed)
+	}
+
+	loc, ok := s.Get("london-uk")
+	if !ok || !loc.Found || loc.Lat != 51.5073 || loc.City != "london" || loc.Country != "uk" {
+		t.Errorf("london-uk = %+v, %v", loc, ok)
+	}
+	// Un lieu introuvable est mis en cache négatif, sans échec
+	if loc, ok := s.Get("atlantis-sea"); !ok || loc.Found {
+		t.Errorf("atlantis-sea = %+v, %v ; attendu introuvable en cache", loc, ok)
+	}
+
+	found, pending := s.Locations([]string{"london-uk", "atlantis-sea", "paris-france"})
+	if len(found) != 1 || pending != 1 {
+		t.Errorf("Locations = %d trouvés, %d en attente ; attendu 1 et 1", len(found), pending)
+	}
+
+	// Les lieux sont persistés et rechargés par un nouveau service
+	reloaded := NewService(&flaky{}, store)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Get("london-uk"); !ok {
+		t.Error("london-uk absent après Load")
+	}
+}
+
+func TestServiceWarmBacksOff(t *testing.T) {
+	ctx := context.Background()
+	g := &flaky{}
+	s := NewService(g, NewMemoryStore())
+
+	if failed := s.Warm(ctx, []string{"london-uk"}); failed != 1 || g.calls != 1 {
+		t.Fatalf("premier Warm: %d échecs, %d appels ; attendu 1 et 1", failed, g.calls)
+	}
+	// Le lieu reste à traiter mais n'est pas retenté avant son délai
+	if failed := s.Warm(ctx, []string{"london-uk"}); failed != 1 || g.calls != 1 {
+		t.Fatalf("Warm pendant le délai: %d échecs, %d appels ; attendu 1 et 1", failed, g.calls)
+	}
+
+	// Délai écoulé : nouvelle tentative, et le délai suivant double
+	s.failures["london-uk"] = failure{count: 1, next: time.Now().Add(-time.Second)}
+	s.Warm(ctx, []string{"london-uk"})
+	if g.calls != 2 {
+		t.Fatalf("Warm après le délai: %d appels, attendu 2", g.calls)
+	}
+	if f := s.failures["london-uk"]; f.count != 2 || time.Until(f.next) <= watchInterval {
+		t.Errorf("échec enregistré = %+v, attendu 2 échecs et un délai > %v", f, watchInterval)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{1, watchInterval},
+		{2, 2 * watchInterval},
+		{3, 4 * watchInterval},
+		{100, maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.n); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, attendu %v", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Store conserve les lieux déjà géocodés
+type Store interface {
+	List(ctx context.Context) ([]Location, error)
+	Put(ctx context.Context, loc Location) error
+}
+
+// PostgresStore stocke les lieux dans la table geo_locations
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crée un store adossé à db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// List retourne tous les lieux connus
+func (s *PostgresStore) List(ctx context.Context) ([]Location, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT slug, city, country, lat, lon, display_name, found, updated_at
+		FROM geo_locations
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []Location
+	for rows.Next() {
+		var loc Location
+		var displayName sql.NullString
+		if err := rows.Scan(&loc.Slug, &loc.City, &loc.Country, &loc.Lat, &loc.Lon, &displayName, &loc.Found, &loc.UpdatedAt); err != nil {
+			return nil, err
+		}
+		loc.DisplayName = displayName.String
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
+
+// Put insère ou met à jour un lieu
+func (s *PostgresStore) Put(ctx context.Context, loc Location) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO geo_locations (slug, city, country, lat, lon, display_name, found, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (slug) DO UPDATE SET
+			city = EXCLUDED.city,
+			country = EXCLUDED.country,
+			lat = EXCLUDED.lat,
+			lon = EXCLUDED.lon,
+			display_name = EXCLUDED.display_name,
+			found = EXCLUDED.found,
+			updated_at = EXCLUDED.updated_at
+	`, loc.Slug, loc.City, loc.Country, loc.Lat, loc.Lon, loc.DisplayName, loc.Found, loc.UpdatedAt)
+	return err
+}
+
+// MemoryStore garde les lieux en mémoire (sans base de données)
+type MemoryStore struct {
+	mu        sync.Mutex
+	locations map[string]Location
+}
+
+// NewMemoryStore crée un store vide
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{locations: make(map[string]Location)}
+}
+
+// List retourne tous les lieux connus
+func (s *MemoryStore) List(ctx context.Context) ([]Location, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Location, 0, len(s.locations))
+	for _, loc := range s.locations {
+		out = append(out, loc)
+	}
+	return out, nil
+}
+
+// Put insère ou met à jour un lieu
+func (s *MemoryStore) Put(ctx context.Context, loc Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locations[loc.Slug] = loc
+	return nil
+}
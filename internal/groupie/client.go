@@ -0,0 +1,151 @@
+package groupie
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+)
+
+// DefaultTimeout est le délai maximal d'un appel à l'API si aucun n'est fourni
+const DefaultTimeout = 10 * time.Second
+
+// maxErrorBody limite la taille du corps recopié dans une APIError
+const maxErrorBody = 512
+
+// APIError décrit une réponse non-2xx de l'API Groupie Trackers
+type APIError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("groupie: %s a répondu %d", e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("groupie: %s a répondu %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+// Client interroge l'API Groupie Trackers et décode ses réponses
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient crée un client pour l'API située à baseURL (ex: cfg.GroupieTrackerAPI)
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout, Transport: metrics.Transport("groupie", logging.Transport(logging.PropagateRequestID(nil)))},
+	}
+}
+
+// BaseURL retourne l'URL racine de l'API
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Artists retourne la liste de tous les artistes
+func (c *Client) Artists(ctx context.Context) ([]Artist, error) {
+	var artists []Artist
+	if err := c.get(ctx, "/artists", &artists); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+// Artist retourne un artiste par son identifiant
+func (c *Client) Artist(ctx context.Context, id int) (*Artist, error) {
+	var artist Artist
+	if err := c.get(ctx, fmt.Sprintf("/artists/%d", id), &artist); err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// Locations retourne les lieux de concerts de tous les artistes
+func (c *Client) Locations(ctx context.Context) ([]Location, error) {
+	var idx LocationIndex
+	if err := c.get(ctx, "/locations", &idx); err != nil {
+		return nil, err
+	}
+	return idx.Index, nil
+}
+
+// Dates retourne les dates de concerts de tous les artistes
+func (c *Client) Dates(ctx context.Context) ([]Dates, error) {
+	var idx DatesIndex
+	if err := c.get(ctx, "/dates", &idx); err != nil {
+		return nil, err
+	}
+	return idx.Index, nil
+}
+
+// Relations retourne la relation lieux ↔ dates de tous les artistes
+func (c *Client) Relations(ctx context.Context) ([]Relation, error) {
+	var idx RelationIndex
+	if err := c.get(ctx, "/relation", &idx); err != nil {
+		return nil, err
+	}
+	return idx.Index, nil
+}
+
+// Ping vérifie que l'API répond (racine de l'API, sans décoder le corps) ;
+// seules les erreurs réseau et les réponses 5xx sont des échecs
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("groupie: requête invalide %s: %w", c.baseURL, err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("groupie: appel %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+
+	if resp.StatusCode >= 500 {
+		return &APIError{URL: c.baseURL, StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// get effectue un GET sur baseURL+path et décode le JSON dans out
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	url := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("groupie: requête invalide %s: %w", url, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("groupie: appel %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &APIError{
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("groupie: décodage %s: %w", url, err)
+	}
+	return nil
+}
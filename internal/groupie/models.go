@@ -0,0 +1,48 @@
+package groupie
+
+// Artist représente un artiste tel que renvoyé par /api/artists
+type Artist struct {
+	ID           int      `json:"id"`
+	Image        string   `json:"image"`
+	Name         string   `json:"name"`
+	Members      []string `json:"members"`
+	CreationDate int      `json:"creationDate"`
+	FirstAlbum   string   `json:"firstAlbum"`
+	Locations    string   `json:"locations"`
+	ConcertDates string   `json:"concertDates"`
+	Relations    string   `json:"relations"`
+}
+
+// Location représente les lieux de concerts d'un artiste (/api/locations)
+type Location struct {
+	ID        int      `json:"id"`
+	Locations []string `json:"locations"`
+	Dates     string   `json:"dates"`
+}
+
+// Dates représente les dates de concerts d'un artiste (/api/dates)
+type Dates struct {
+	ID    int      `json:"id"`
+	Dates []string `json:"dates"`
+}
+
+// Relation associe chaque lieu de concert à ses dates (/api/relation)
+type Relation struct {
+	ID             int                 `json:"id"`
+	DatesLocations map[string][]string `json:"datesLocations"`
+}
+
+// LocationIndex est l'enveloppe {"index": [...]} de /api/locations
+type LocationIndex struct {
+	Index []Location `json:"index"`
+}
+
+// DatesIndex est l'enveloppe {"index": [...]} de /api/dates
+type DatesIndex struct {
+	Index []Dates `json:"index"`
+}
+
+// RelationIndex est l'enveloppe {"index": [...]} de /api/relation
+type RelationIndex struct {
+	Index []Relation `json:"index"`
+}
@@ -0,0 +1,27 @@
+package groupie
+
+import (
+	"strings"
+	"time"
+)
+
+// dateLayout est le format des dates de l'API (ex: "14-12-1973")
+const dateLayout = "02-01-2006"
+
+// ParseDate lit une date de l'API ("14-12-1973"). L'astérisque présent
+// devant certaines dates de /api/dates ("*23-08-2019") est ignoré.
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse(dateLayout, strings.TrimPrefix(strings.TrimSpace(s), "*"))
+}
+
+// ParseLocation découpe un lieu de l'API en ville et pays :
+// "north_carolina-usa" -> ("north carolina", "usa")
+func ParseLocation(slug string) (city, country string) {
+	slug = strings.TrimSpace(slug)
+	if i := strings.LastIndex(slug, "-"); i >= 0 {
+		city, country = slug[:i], slug[i+1:]
+	} else {
+		city = slug
+	}
+	return strings.ReplaceAll(city, "_", " "), strings.ReplaceAll(country, "_", " ")
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/models"
+)
+
+// AnonymousFavoritesCookie garde les favoris des visiteurs non connectés
+const AnonymousFavoritesCookie = "groupie_favorites"
+
+// maxAnonymousCookieSize reste sous la limite de 4 Ko des navigateurs
+const maxAnonymousCookieSize = 3500
+
+// anonymousFavorite est la forme compacte d'un favori stocké dans le cookie
+type anonymousFavorite struct {
+	ArtistID    int    `json:"a"`
+	ArtistName  string `json:"n"`
+	ArtistImage string `json:"i,omitempty"`
+	CreatedAt   int64  `json:"t"`
+}
+
+// AnonymousFavorites lit les favoris du cookie, du plus récent au plus ancien
+func AnonymousFavorites(r *http.Request) []models.Favorite {
+	favorites := []models.Favorite{}
+	c, err := r.Cookie(AnonymousFavoritesCookie)
+	if err != nil {
+		return favorites
+	}
+	data, err := base64.RawURLEncoding.DecodeString(c.Value)
+	if err != nil {
+		return favorites
+	}
+	var stored []anonymousFavorite
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return favorites
+	}
+
+	for _, f := range stored {
+		favorites = append(favorites, models.Favorite{
+			ArtistID:    f.ArtistID,
+			ArtistName:  f.ArtistName,
+			ArtistImage: f.ArtistImage,
+			CreatedAt:   time.Unix(f.CreatedAt, 0),
+		})
+	}
+	return favorites
+}
+
+// SaveAnonymousFavorites écrit les favoris dans le cookie (les plus anciens
+// sont abandonnés si le cookie devient trop gros). Une liste vide efface le cookie.
+// Retourne false, sans toucher au cookie, si même le premier favori ne tient pas.
+func SaveAnonymousFavorites(w http.ResponseWriter, favorites []models.Favorite) bool {
+	if len(favorites) == 0 {
+		http.SetCookie(w, &http.Cookie{
+			Name:     AnonymousFavoritesCookie,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return true
+	}
+
+	stored := make([]anonymousFavorite, 0, len(favorites))
+	for _, f := range favorites {
+		stored = append(stored, anonymousFavorite{
+			ArtistID:    f.ArtistID,
+			ArtistName:  f.ArtistName,
+			ArtistImage: f.ArtistImage,
+			CreatedAt:   f.CreatedAt.Unix(),
+		})
+	}
+
+	for n := len(stored); n > 0; n-- {
+		data, err := json.Marshal(stored[:n])
+		if err != nil {
+			return false
+		}
+		value := base64.RawURLEncoding.EncodeToString(data)
+		if len(value) > maxAnonymousCookieSize {
+			continue
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     AnonymousFavoritesCookie,
+			Value:    value,
+			Path:     "/",
+			MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return true
+	}
+	return false
+}
+
+// AddAnonymousFavorite ajoute un favori en tête du cookie du visiteur
+// (sans doublon) et retourne le favori enregistré ; false si le favori
+// est trop volumineux pour le cookie
+func AddAnonymousFavorite(w http.ResponseWriter, r *http.Request, fav models.Favorite) (models.Favorite, bool) {
+	favorites := AnonymousFavorites(r)
+	for _, f := range favorites {
+		if f.ArtistID == fav.ArtistID {
+			return f, true
+		}
+	}
+	fav.ID = 0
+	fav.CreatedAt = time.Now()
+	if !SaveAnonymousFavorites(w, append([]models.Favorite{fav}, favorites...)) {
+		return models.Favorite{}, false
+	}
+	return fav, true
+}
+
+// RemoveAnonymousFavorite retire un artiste du cookie du visiteur ;
+// retourne false s'il n'y était pas
+func RemoveAnonymousFavorite(w http.ResponseWriter, r *http.Request, artistID int) bool {
+	favorites := AnonymousFavorites(r)
+	for i, f := range favorites {
+		if f.ArtistID == artistID {
+			SaveAnonymousFavorites(w, append(favorites[:i], favorites[i+1:]...))
+			return true
+		}
+	}
+	return false
+}
+
+// MergeAnonymousFavorites rattache au compte userID les favoris du cookie
+// puis efface le cookie (appelé à la connexion)
+func MergeAnonymousFavorites(w http.ResponseWriter, r *http.Request, store favorites.Store, userID int) error {
+	favs := AnonymousFavorites(r)
+	if len(favs) == 0 {
+		return nil
+	}
+
+	if err := favorites.Merge(r.Context(), store, userID, favs); err != nil {
+		return err
+	}
+	SaveAnonymousFavorites(w, nil)
+	return nil
+}
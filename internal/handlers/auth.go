@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/logging"
+)
+
+// loginRequest est le corps attendu par POST /api/login
+type loginRequest struct {
+	IDUtilisateur int    `json:"id_utilisateur"`
+	Password      string `json:"password"`
+	Remember      bool   `json:"remember"`
+}
+
+// Register gère POST /api/register
+func Register(users *auth.Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if users == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		var reg auth.Registration
+		if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+			writeError(w, http.StatusBadRequest, "Données invalides")
+			return
+		}
+
+		user, err := users.Register(r.Context(), reg)
+		if err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, user)
+	}
+}
+
+// Login gère POST /api/login : vérifie les identifiants et ouvre une session
+// (cookie HTTP-only, prolongé à 30 jours avec "remember")
+func Login(users *auth.Users, sessions *auth.Sessions, store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if users == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		var req loginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Données invalides")
+			return
+		}
+		fields := make(map[string]string)
+		if req.IDUtilisateur <= 0 {
+			fields["id_utilisateur"] = "requis"
+		}
+		if req.Password == "" {
+			fields["password"] = "requis"
+		}
+		if len(fields) > 0 {
+			writeAuthError(w, r, &auth.ValidationError{Fields: fields})
+			return
+		}
+
+		user, err := users.Authenticate(r.Context(), req.IDUtilisateur, req.Password)
+		if err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+
+		if err := sessions.Create(r.Context(), w, r, user, req.Remember); err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+
+		// Rattacher au compte les favoris ajoutés en anonyme
+		if err := MergeAnonymousFavorites(w, r, store, user.ID); err != nil {
+			logging.FromContext(r.Context()).Warn("Erreur fusion des favoris anonymes", "err", err)
+		}
+
+		writeJSON(w, http.StatusOK, user)
+	}
+}
+
+// Logout gère POST /api/logout : révoque la session courante et efface le cookie
+func Logout(sessions *auth.Sessions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if sessions == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		if err := sessions.Revoke(r.Context(), w, r); err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Déconnexion réussie"})
+	}
+}
+
+// Me gère GET /api/me : utilisateur de la session courante
+func Me(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "Non connecté")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// tokenRequest est le corps attendu par /api/token/refresh et /api/token/revoke
+type tokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	Token        string `json:"token"`
+}
+
+// Token gère POST /api/token : échange identifiants contre jetons JWT
+// (accès de courte durée + rafraîchissement) pour les clients sans cookies
+func Token(users *auth.Users, tokens *auth.Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if users == nil || tokens == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		var req loginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IDUtilisateur <= 0 || req.Password == "" {
+			writeError(w, http.StatusBadRequest, "id_utilisateur et password requis")
+			return
+		}
+
+		user, err := users.Authenticate(r.Context(), req.IDUtilisateur, req.Password)
+		if err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+
+		pair, err := tokens.Issue(user)
+		if err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pair)
+	}
+}
+
+// RefreshToken gère POST /api/token/refresh : {"refresh_token": "..."} → nouvelle paire
+func RefreshToken(tokens *auth.Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if tokens == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		var req tokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+			writeError(w, http.StatusBadRequest, "refresh_token requis")
+			return
+		}
+
+		pair, err := tokens.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pair)
+	}
+}
+
+// RevokeToken gère POST /api/token/revoke : {"token": "..."} (accès ou rafraîchissement)
+func RevokeToken(tokens *auth.Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+		if tokens == nil {
+			writeError(w, http.StatusServiceUnavailable, "Base de données indisponible")
+			return
+		}
+
+		var req tokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+			writeError(w, http.StatusBadRequest, "token requis")
+			return
+		}
+
+		if err := tokens.Revoke(r.Context(), req.Token); err != nil {
+			writeAuthError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Jeton révoqué"})
+	}
+}
+
+// writeAuthError traduit les erreurs d'authentification en réponses JSON
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *auth.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  "Données invalides",
+			"fields": verr.Fields,
+		})
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		writeError(w, http.StatusUnauthorized, "Identifiant ou mot de passe incorrect")
+	case errors.Is(err, auth.ErrInvalidToken):
+		writeError(w, http.StatusUnauthorized, "Jeton invalide ou expiré")
+	default:
+		logging.FromContext(r.Context()).Error("Erreur authentification", "err", err)
+		writeError(w, http.StatusInternalServerError, "Erreur serveur")
+	}
+}
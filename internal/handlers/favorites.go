@@ -1,197 +1,187 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
+	"unicode/utf8"
 
-	"groupiepersso/internal/database"
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/logging"
 	"groupiepersso/internal/models"
 )
 
-func ensureDBReady(w http.ResponseWriter) bool {
-	if database.DB != nil {
-		return true
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(http.StatusServiceUnavailable)
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"error": "Base de données indisponible",
-	})
-	return false
-}
-
-// GetFavorites retourne tous les artistes favoris
-func GetFavorites(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+// MaxFavoriteBody limite la taille du corps d'une requête d'ajout de favori
+const MaxFavoriteBody = 4 << 10
 
-	if !ensureDBReady(w) {
-		return
-	}
+// Longueurs maximales des champs d'un favori (colonnes de la table favorites)
+const (
+	maxArtistName  = 255
+	maxArtistImage = 512
+)
 
-	rows, err := database.DB.Query(`
-		SELECT id, artist_id, artist_name, artist_image, created_at 
-		FROM favorites 
-		ORDER BY created_at DESC
-	`)
-	if err != nil {
-		log.Printf("Erreur lors de la récupération des favoris: %v", err)
-		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
+// ValidFavorite vérifie la longueur des champs envoyés par le client
+func ValidFavorite(fav models.Favorite) bool {
+	return utf8.RuneCountInString(fav.ArtistName) <= maxArtistName &&
+		utf8.RuneCountInString(fav.ArtistImage) <= maxArtistImage
+}
 
-	var favorites []models.Favorite
-	for rows.Next() {
-		var fav models.Favorite
-		var createdAt sql.NullTime
-		if err := rows.Scan(&fav.ID, &fav.ArtistID, &fav.ArtistName, &fav.ArtistImage, &createdAt); err != nil {
-			log.Printf("Erreur lors du scan: %v", err)
-			continue
-		}
-		if createdAt.Valid {
-			fav.CreatedAt = createdAt.Time
+// GetFavorites retourne les artistes favoris de l'utilisateur connecté,
+// ou ceux du cookie pour un visiteur anonyme
+func GetFavorites(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
 		}
-		favorites = append(favorites, fav)
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	json.NewEncoder(w).Encode(favorites)
-}
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			writeJSON(w, http.StatusOK, AnonymousFavorites(r))
+			return
+		}
 
-// AddFavorite ajoute un artiste aux favoris
-func AddFavorite(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+		favs, err := store.List(r.Context(), user.ID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Erreur lors de la récupération des favoris", "err", err)
+			http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+			return
+		}
 
-	if !ensureDBReady(w) {
-		return
+		writeJSON(w, http.StatusOK, favs)
 	}
+}
 
-	var fav models.Favorite
-	if err := json.NewDecoder(r.Body).Decode(&fav); err != nil {
-		log.Printf("Erreur lors du décodage JSON: %v", err)
-		http.Error(w, "Données invalides", http.StatusBadRequest)
-		return
-	}
+// AddFavorite ajoute un artiste aux favoris de l'utilisateur connecté,
+// ou au cookie pour un visiteur anonyme
+func AddFavorite(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-	// Insérer le favori (UNIQUE constraint empêchera les doublons)
-	var createdAt sql.NullTime
-	err := database.DB.QueryRow(`
-		INSERT INTO favorites (artist_id, artist_name, artist_image) 
-		VALUES ($1, $2, $3)
-		ON CONFLICT (artist_id) DO NOTHING
-		RETURNING id, created_at
-	`, fav.ArtistID, fav.ArtistName, fav.ArtistImage).Scan(&fav.ID, &createdAt)
+		var fav models.Favorite
+		r.Body = http.MaxBytesReader(w, r.Body, MaxFavoriteBody)
+		if err := json.NewDecoder(r.Body).Decode(&fav); err != nil {
+			logging.FromContext(r.Context()).Debug("Erreur lors du décodage JSON", "err", err)
+			http.Error(w, "Données invalides", http.StatusBadRequest)
+			return
+		}
+		if !ValidFavorite(fav) {
+			http.Error(w, "Données invalides", http.StatusBadRequest)
+			return
+		}
 
-	if err != nil {
-		// Si aucune ligne retournée, c'est que l'artiste est déjà en favori
-		log.Printf("Artiste déjà en favoris ou erreur: %v", err)
-		// On récupère quand même les infos existantes
-		err = database.DB.QueryRow(`
-			SELECT id, created_at FROM favorites WHERE artist_id = $1
-		`, fav.ArtistID).Scan(&fav.ID, &createdAt)
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			saved, ok := AddAnonymousFavorite(w, r, fav)
+			if !ok {
+				http.Error(w, "Favori trop volumineux", http.StatusRequestEntityTooLarge)
+				return
+			}
+			writeJSON(w, http.StatusCreated, saved)
+			return
+		}
 
+		// Un artiste déjà en favori retourne le favori existant
+		saved, _, err := store.Add(r.Context(), user.ID, fav)
 		if err != nil {
+			logging.FromContext(r.Context()).Error("Erreur lors de l'ajout du favori", "err", err)
 			http.Error(w, "Erreur serveur", http.StatusInternalServerError)
 			return
 		}
-	}
 
-	if createdAt.Valid {
-		fav.CreatedAt = createdAt.Time
+		writeJSON(w, http.StatusCreated, saved)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(fav)
 }
 
-// RemoveFavorite supprime un artiste des favoris
-func RemoveFavorite(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+// RemoveFavorite supprime un artiste des favoris de l'utilisateur connecté,
+// ou du cookie pour un visiteur anonyme
+func RemoveFavorite(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-	if !ensureDBReady(w) {
-		return
-	}
+		artistID, ok := artistIDParam(w, r)
+		if !ok {
+			return
+		}
 
-	// Récupérer l'ID de l'artiste depuis l'URL query parameter
-	artistIDStr := r.URL.Query().Get("artist_id")
-	if artistIDStr == "" {
-		http.Error(w, "artist_id requis", http.StatusBadRequest)
-		return
-	}
+		user := auth.UserFromContext(r.Context())
+		var removed bool
+		if user == nil {
+			removed = RemoveAnonymousFavorite(w, r, artistID)
+		} else {
+			var err error
+			removed, err = store.Remove(r.Context(), user.ID, artistID)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("Erreur lors de la suppression", "err", err)
+				http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+				return
+			}
+		}
 
-	artistID, err := strconv.Atoi(artistIDStr)
-	if err != nil {
-		http.Error(w, "artist_id invalide", http.StatusBadRequest)
-		return
+		if !removed {
+			http.Error(w, "Favori non trouvé", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Favori supprimé avec succès"})
 	}
+}
 
-	result, err := database.DB.Exec(`DELETE FROM favorites WHERE artist_id = $1`, artistID)
-	if err != nil {
-		log.Printf("Erreur lors de la suppression: %v", err)
-		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
-		return
-	}
+// CheckFavorite vérifie si un artiste est dans les favoris de l'utilisateur (ou du cookie)
+func CheckFavorite(store favorites.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Favori non trouvé", http.StatusNotFound)
-		return
-	}
+		artistID, ok := artistIDParam(w, r)
+		if !ok {
+			return
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Favori supprimé avec succès"})
-}
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			exists := false
+			for _, f := range AnonymousFavorites(r) {
+				if f.ArtistID == artistID {
+					exists = true
+					break
+				}
+			}
+			writeJSON(w, http.StatusOK, map[string]bool{"is_favorite": exists})
+			return
+		}
 
-// CheckFavorite vérifie si un artiste est en favori
-func CheckFavorite(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
-		return
-	}
+		exists, err := store.Exists(r.Context(), user.ID, artistID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Erreur lors de la vérification", "err", err)
+			http.Error(w, "Erreur serveur", http.StatusInternalServerError)
+			return
+		}
 
-	if !ensureDBReady(w) {
-		return
+		writeJSON(w, http.StatusOK, map[string]bool{"is_favorite": exists})
 	}
+}
 
+// artistIDParam lit le paramètre artist_id ; écrit une erreur 400 s'il est absent ou invalide
+func artistIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
 	artistIDStr := r.URL.Query().Get("artist_id")
 	if artistIDStr == "" {
 		http.Error(w, "artist_id requis", http.StatusBadRequest)
-		return
+		return 0, false
 	}
 
 	artistID, err := strconv.Atoi(artistIDStr)
 	if err != nil {
 		http.Error(w, "artist_id invalide", http.StatusBadRequest)
-		return
+		return 0, false
 	}
-
-	var exists bool
-	err = database.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM favorites WHERE artist_id = $1)`, artistID).Scan(&exists)
-	if err != nil {
-		log.Printf("Erreur lors de la vérification: %v", err)
-		http.Error(w, "Erreur serveur", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	json.NewEncoder(w).Encode(map[string]bool{"is_favorite": exists})
+	return artistID, true
 }
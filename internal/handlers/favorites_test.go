@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/models"
+)
+
+// request construit une requête, authentifiée si userID > 0
+func request(method, target, body string, userID int) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userID > 0 {
+		r = r.WithContext(auth.WithUser(r.Context(), &models.User{ID: userID}))
+	}
+	return r
+}
+
+// serve exécute h et retourne la réponse enregistrée
+func serve(h http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h(w, r)
+	return w
+}
+
+func TestFavoritesAPI(t *testing.T) {
+	store := favorites.NewMemoryStore()
+	add := AddFavorite(store)
+	remove := RemoveFavorite(store)
+	check := CheckFavorite(store)
+	list := GetFavorites(store)
+
+	// Ajout puis ajout en double : le favori existant est retourné
+	var first, second models.Favorite
+	w := serve(add, request(http.MethodPost, "/api/favorites", `{"artist_id":1,"artist_name":"Queen"}`, 1))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("ajout: statut %d, attendu 201", w.Code)
+	}
+	json.NewDecoder(w.Body).Decode(&first)
+	w = serve(add, request(http.MethodPost, "/api/favorites", `{"artist_id":1,"artist_name":"Queen"}`, 1))
+	json.NewDecoder(w.Body).Decode(&second)
+	if w.Code != http.StatusCreated || second.ID != first.ID {
+		t.Fatalf("ajout en double: statut %d, id %d ; attendu 201 et id %d", w.Code, second.ID, first.ID)
+	}
+	if favs, _ := store.List(context.Background(), 1); len(favs) != 1 {
+		t.Fatalf("%d favoris après l'ajout en double, attendu 1", len(favs))
+	}
+
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+		r    *http.Request
+		code int
+		body string
+	}{
+		{"favori présent", check, request(http.MethodGet, "/api/favorites/check?artist_id=1", "", 1), http.StatusOK, `{"is_favorite":true}`},
+		{"favori absent", check, request(http.MethodGet, "/api/favorites/check?artist_id=2", "", 1), http.StatusOK, `{"is_favorite":false}`},
+		{"artist_id invalide", check, request(http.MethodGet, "/api/favorites/check?artist_id=x", "", 1), http.StatusBadRequest, ""},
+		{"JSON invalide", add, request(http.MethodPost, "/api/favorites", `{`, 1), http.StatusBadRequest, ""},
+		{"nom trop long", add, request(http.MethodPost, "/api/favorites", `{"artist_id":2,"artist_name":"`+strings.Repeat("a", 256)+`"}`, 1), http.StatusBadRequest, ""},
+		{"corps trop gros", add, request(http.MethodPost, "/api/favorites", `{"artist_id":2,"x":"`+strings.Repeat("a", MaxFavoriteBody)+`"}`, 1), http.StatusBadRequest, ""},
+		{"méthode", list, request(http.MethodPost, "/api/favorites", "", 1), http.StatusMethodNotAllowed, ""},
+		{"autre utilisateur", check, request(http.MethodGet, "/api/favorites/check?artist_id=1", "", 2), http.StatusOK, `{"is_favorite":false}`},
+		{"liste d'un autre utilisateur", list, request(http.MethodGet, "/api/favorites", "", 2), http.StatusOK, `[]`},
+		{"retrait par un autre utilisateur", remove, request(http.MethodDelete, "/api/favorites?artist_id=1", "", 2), http.StatusNotFound, ""},
+		{"retrait", remove, request(http.MethodDelete, "/api/favorites?artist_id=1", "", 1), http.StatusOK, ""},
+		{"retrait d'un favori absent", remove, request(http.MethodDelete, "/api/favorites?artist_id=1", "", 1), http.StatusNotFound, ""},
+		{"favori retiré", check, request(http.MethodGet, "/api/favorites/check?artist_id=1", "", 1), http.StatusOK, `{"is_favorite":false}`},
+	}
+	for _, tt := range tests {
+		w := serve(tt.h, tt.r)
+		if w.Code != tt.code {
+			t.Errorf("%s: statut %d, attendu %d (%s)", tt.name, w.Code, tt.code, w.Body)
+			continue
+		}
+		if tt.body != "" && strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("%s: corps %s, attendu %s", tt.name, w.Body, tt.body)
+		}
+	}
+}
+
+func TestFavoritesAPIAnonymous(t *testing.T) {
+	store := favorites.NewMemoryStore()
+
+	// Sans utilisateur, le favori va dans le cookie et pas dans le store
+	w := serve(AddFavorite(store), request(http.MethodPost, "/api/favorites", `{"artist_id":1,"artist_name":"Queen"}`, 0))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("ajout anonyme: statut %d, attendu 201", w.Code)
+	}
+	if st, _ := store.Stats(context.Background()); st.Favorites != 0 {
+		t.Fatalf("%d favoris dans le store, attendu 0", st.Favorites)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != AnonymousFavoritesCookie {
+		t.Fatalf("cookies = %v", cookies)
+	}
+
+	r := request(http.MethodGet, "/api/favorites/check?artist_id=1", "", 0)
+	r.AddCookie(cookies[0])
+	if w := serve(CheckFavorite(store), r); strings.TrimSpace(w.Body.String()) != `{"is_favorite":true}` {
+		t.Errorf("check anonyme: %s", w.Body)
+	}
+
+	// Un utilisateur connecté ne voit pas les favoris du cookie
+	r = request(http.MethodGet, "/api/favorites/check?artist_id=1", "", 1)
+	r.AddCookie(cookies[0])
+	if w := serve(CheckFavorite(store), r); strings.TrimSpace(w.Body.String()) != `{"is_favorite":false}` {
+		t.Errorf("check connecté: %s", w.Body)
+	}
+
+	r = request(http.MethodDelete, "/api/favorites?artist_id=1", "", 0)
+	r.AddCookie(cookies[0])
+	if w := serve(RemoveFavorite(store), r); w.Code != http.StatusOK {
+		t.Errorf("retrait anonyme: statut %d, attendu 200", w.Code)
+	}
+}
+
+func TestAddAnonymousFavoriteTooLarge(t *testing.T) {
+	w := httptest.NewRecorder()
+	if _, ok := AddAnonymousFavorite(w, httptest.NewRequest(http.MethodPost, "/", nil), models.Favorite{ArtistID: 1, ArtistName: "Queen"}); !ok {
+		t.Fatal("premier favori refusé")
+	}
+	cookie := w.Result().Cookies()[0]
+
+	// Un favori qui ne tient pas seul dans le cookie est refusé sans effacer les autres
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	huge := models.Favorite{ArtistID: 2, ArtistName: strings.Repeat("<", maxArtistName), ArtistImage: strings.Repeat("<", maxArtistImage)}
+	if _, ok := AddAnonymousFavorite(w, r, huge); ok {
+		t.Fatal("favori trop volumineux accepté")
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 0 {
+		t.Errorf("cookie modifié: %v", cookies)
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/geo"
+)
+
+// GeoLocations gère GET /api/locations/geo : coordonnées des lieux de concerts
+// déjà géocodés par le serveur, et nombre de lieux encore en attente
+func GeoLocations(svc *geo.Service, cat *catalog.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+
+		snap := cat.Snapshot()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, "Catalogue en cours de chargement")
+			return
+		}
+
+		locations, pending := svc.Locations(snap.LocationNames())
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"locations": locations,
+			"pending":   pending,
+		})
+	}
+}
+
+// ArtistTour gère GET /api/artists/{id}/tour.geojson : lieux de concerts
+// d'un artiste et trajet chronologique de sa tournée au format GeoJSON
+func ArtistTour(svc *geo.Service, cat *catalog.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "id invalide")
+			return
+		}
+
+		snap := cat.Snapshot()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, "Catalogue en cours de chargement")
+			return
+		}
+
+		entry, ok := snap.Entry(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Artiste non trouvé")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/geo+json")
+		json.NewEncoder(w).Encode(geo.Tour(entry, svc.Get))
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/preview"
+)
+
+// previewResponse est la réponse de GET /api/artists/{id}/preview
+type previewResponse struct {
+	ArtistID   int    `json:"artist_id"`
+	Artist     string `json:"artist"`
+	Title      string `json:"title"`
+	Album      string `json:"album,omitempty"`
+	ArtworkURL string `json:"artwork_url,omitempty"`
+	Provider   string `json:"provider"`
+	PreviewURL string `json:"preview_url"` // via /api/audio-proxy
+	SourceURL  string `json:"source_url"`  // URL d'origine sur le CDN
+}
+
+// ArtistPreview gère GET /api/artists/{id}/preview : extrait musical de
+// 30 s de l'artiste, servi à travers le proxy audio
+func ArtistPreview(svc *preview.Service, cat *catalog.Catalog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "id invalide")
+			return
+		}
+
+		snap := cat.Snapshot()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, "Catalogue en cours de chargement")
+			return
+		}
+
+		entry, ok := snap.Entry(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Artiste non trouvé")
+			return
+		}
+
+		res, err := svc.Resolve(r.Context(), id, entry.Artist.Name)
+		if errors.Is(err, preview.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Aucun extrait trouvé")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadGateway, "Fournisseurs d'extraits indisponibles")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, previewResponse{
+			ArtistID:   id,
+			Artist:     entry.Artist.Name,
+			Title:      res.Track.Title,
+			Album:      res.Track.Album,
+			ArtworkURL: res.Track.ArtworkURL,
+			Provider:   res.Provider,
+			PreviewURL: "/api/audio-proxy?url=" + url.QueryEscape(res.Track.PreviewURL),
+			SourceURL:  res.Track.PreviewURL,
+		})
+	}
+}
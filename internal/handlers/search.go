@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"groupiepersso/internal/search"
+)
+
+// Search gère GET /api/search?q=&page=&limit= (accepte aussi les filtres de /api/artists)
+func Search(engine *search.Engine) http.HandlerFunc {
+	return runSearch(engine, true)
+}
+
+// Artists gère GET /api/artists : liste filtrée des artistes
+// (creation_from, creation_to, album_from, album_to, members, country, city)
+// avec les comptages par facette
+func Artists(engine *search.Engine) http.HandlerFunc {
+	return runSearch(engine, false)
+}
+
+// runSearch exécute une recherche filtrée ; withText indique si le paramètre q est pris en compte
+func runSearch(engine *search.Engine, withText bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filter, err := search.ParseFilter(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		idx := engine.Index()
+		if idx == nil {
+			writeError(w, http.StatusServiceUnavailable, "Catalogue en cours de chargement")
+			return
+		}
+
+		q := search.Query{
+			Filter: filter,
+			Page:   queryInt(r, "page", 1),
+			Limit:  queryInt(r, "limit", search.DefaultLimit),
+		}
+		if withText {
+			q.Text = r.URL.Query().Get("q")
+		}
+		writeJSON(w, http.StatusOK, idx.Search(q))
+	}
+}
+
+// Suggest gère GET /api/suggest?q=&limit=
+func Suggest(engine *search.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+			return
+		}
+
+		idx := engine.Index()
+		if idx == nil {
+			writeError(w, http.StatusServiceUnavailable, "Catalogue en cours de chargement")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, idx.Suggest(r.URL.Query().Get("q"), queryInt(r, "limit", search.DefaultSuggestLimit)))
+	}
+}
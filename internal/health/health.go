@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/logging"
+)
+
+// Statuts d'une vérification et de l'ensemble
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // une dépendance optionnelle est en échec
+	StatusFail     = "fail"
+)
+
+// DefaultTimeout borne la durée de chaque vérification
+const DefaultTimeout = 2 * time.Second
+
+// Check vérifie une dépendance ; nil si elle est disponible
+type Check func(ctx context.Context) error
+
+type check struct {
+	name     string
+	required bool
+	fn       Check
+}
+
+// Result est l'état d'une dépendance dans la réponse de /readyz
+type Result struct {
+	Status     string  `json:"status"`
+	Required   bool    `json:"required"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Report est la réponse de /readyz
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker vérifie les dépendances du serveur pour /readyz. Seules les
+// dépendances requises rendent le serveur « non prêt » (503) ; l'échec d'une
+// dépendance optionnelle est signalé comme dégradé (200).
+type Checker struct {
+	timeout  time.Duration
+	required map[string]bool
+	checks   []check
+}
+
+// New crée un checker ; required liste les dépendances nécessaires
+// (ex: READY_REQUIRED="database,catalog")
+func New(required string, timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, required: parseRequired(required)}
+}
+
+// Requires indique si name fait partie de la liste required (format de New)
+func Requires(required, name string) bool {
+	return parseRequired(required)[name]
+}
+
+// parseRequired lit une liste de dépendances séparées par des virgules
+func parseRequired(required string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(required, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Add déclare une dépendance à vérifier
+func (c *Checker) Add(name string, fn Check) {
+	c.checks = append(c.checks, check{name: name, required: c.required[name], fn: fn})
+}
+
+// Unknown retourne les dépendances requises qui n'ont pas été déclarées
+// (faute de frappe dans la configuration)
+func (c *Checker) Unknown() []string {
+	declared := make(map[string]bool)
+	for _, ch := range c.checks {
+		declared[ch.name] = true
+	}
+	var unknown []string
+	for name := range c.required {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// Run exécute toutes les vérifications en parallèle
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, ch := range c.checks {
+		wg.Add(1)
+		go func(ch check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := ch.fn(ctx)
+			res := Result{
+				Status:     StatusOK,
+				Required:   ch.required,
+				DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				res.Status, res.Error = StatusFail, logging.Redact(err.Error())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[ch.name] = res
+			switch {
+			case err == nil:
+			case ch.required:
+				report.Status = StatusFail
+			case report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+		}(ch)
+	}
+	wg.Wait()
+	return report
+}
+
+// Live sert /healthz : le processus répond, sans vérifier ses dépendances
+func Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": StatusOK})
+}
+
+// Ready sert /readyz : 200 si toutes les dépendances requises sont
+// disponibles, 503 sinon, avec le détail de chaque dépendance
+func (c *Checker) Ready() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		status := http.StatusOK
+		if report.Status == StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// Cached mémorise le résultat de fn pendant ttl : une sonde appelée toutes
+// les quelques secondes n'interroge pas un service externe à chaque fois
+func Cached(ttl time.Duration, fn Check) Check {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		last    error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checked.IsZero() && time.Since(checked) < ttl {
+			return last
+		}
+		last = fn(ctx)
+		checked = time.Now()
+		return last
+	}
+}
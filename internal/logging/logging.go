@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// redacted remplace les valeurs sensibles dans les logs
+const redacted = "[REDACTED]"
+
+// sensitiveKeys sont les noms d'attributs dont la valeur n'est jamais écrite
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie"}
+
+// Motifs masqués dans tous les messages et valeurs texte
+var (
+	// postgres://user:motdepasse@hôte -> postgres://user:[REDACTED]@hôte
+	urlPassword = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://[^:/@\s]*):[^\s/]*@`)
+	// password=... dans une chaîne de connexion ou une query string
+	keyValueSecret = regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api_?key)=([^\s&]+)`)
+	// Authorization: Bearer <jeton>
+	bearerToken = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/-]+=*`)
+)
+
+// Setup installe le logger par défaut selon LOG_LEVEL ("debug", "info",
+// "warn", "error") et ENVIRONMENT : JSON en production, texte lisible en
+// développement. Les appels restants au package log passent aussi par lui.
+func Setup(level, environment string) *slog.Logger {
+	logger := New(os.Stderr, level, environment)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New crée un logger écrivant dans w, avec masquage des secrets
+func New(w io.Writer, level, environment string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level), ReplaceAttr: redact}
+	if isDevelopment(environment) {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// ParseLevel lit un niveau de log ; une valeur inconnue donne info
+func ParseLevel(s string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func isDevelopment(environment string) bool {
+	switch strings.ToLower(environment) {
+	case "development", "dev", "local":
+		return true
+	}
+	return false
+}
+
+// Redact masque les mots de passe d'URL, les paires password=... et les
+// jetons Bearer contenus dans s
+func Redact(s string) string {
+	s = urlPassword.ReplaceAllString(s, "${1}:"+redacted+"@")
+	s = keyValueSecret.ReplaceAllString(s, "${1}="+redacted)
+	return bearerToken.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// redact est le ReplaceAttr des handlers : valeur supprimée pour les clés
+// sensibles, motifs masqués dans les autres textes (message compris) et erreurs
+func redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(Redact(err.Error()))
+		}
+	}
+	return a
+}
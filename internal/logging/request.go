@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RequestIDHeader porte l'identifiant de requête, en entrée comme en sortie
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limite les identifiants reçus à un format sûr pour les logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+	accessKey
+)
+
+// access accumule les informations du log d'accès pendant la requête
+type access struct {
+	userID int
+}
+
+// NewContext retourne un contexte portant logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext retourne le logger de la requête, ou le logger par défaut
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// RequestID retourne l'identifiant de la requête en cours ("" hors requête)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// NewRequestID génère un identifiant aléatoire de 16 caractères hexadécimaux
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID retourne un contexte portant id et un logger qui l'ajoute à
+// toutes ses lignes (attribut request_id). Sert aussi aux tâches de fond
+// (rafraîchissement du catalogue) pour corréler leurs appels sortants.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, id)
+	return NewContext(ctx, FromContext(ctx).With("request_id", id))
+}
+
+// WithUser ajoute l'utilisateur authentifié au logger de la requête et au
+// log d'accès
+func WithUser(ctx context.Context, userID int) context.Context {
+	if a, ok := ctx.Value(accessKey).(*access); ok {
+		a.userID = userID
+	}
+	return NewContext(ctx, FromContext(ctx).With("user_id", userID))
+}
+
+// Middleware reprend l'en-tête X-Request-ID du client (ou en génère un), le
+// renvoie dans la réponse, et écrit une ligne de log d'accès par requête :
+// méthode, chemin, statut, octets, durée et utilisateur
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		a := &access{}
+		ctx := WithRequestID(context.WithValue(r.Context(), accessKey, a), id)
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if a.userID != 0 {
+			attrs = append(attrs, slog.Int("user_id", a.userID))
+		}
+		FromContext(ctx).LogAttrs(ctx, level, "Requête HTTP", attrs...)
+	})
+}
+
+// recorder mémorise le statut et la taille de la réponse
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status, rec.wroteHeader = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap donne accès au ResponseWriter d'origine (http.ResponseController)
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// slowUpstream est la durée au-delà de laquelle un appel sortant est signalé
+const slowUpstream = 2 * time.Second
+
+// transport journalise la durée de chaque appel sortant
+type transport struct {
+	base http.RoundTripper
+}
+
+// Transport enveloppe base (http.DefaultTransport si nil) : le statut et la
+// durée de chaque requête sortante sont journalisés (debug, ou warn au-delà
+// de 2 s). L'identifiant de requête n'est pas transmis : voir PropagateRequestID.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	level := slog.LevelDebug
+	if err != nil || elapsed > slowUpstream {
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("host", req.URL.Host),
+		slog.String("path", req.URL.Path),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	FromContext(ctx).LogAttrs(ctx, level, "Appel sortant", attrs...)
+	return resp, err
+}
+
+// propagate ajoute l'en-tête X-Request-ID aux requêtes sortantes
+type propagate struct {
+	base http.RoundTripper
+}
+
+// PropagateRequestID enveloppe base (http.DefaultTransport si nil) pour que
+// chaque requête sortante porte le X-Request-ID de son contexte. L'identifiant
+// peut venir du client : à réserver aux services dont on corrèle les journaux
+// (API Groupie Trackers), pas aux services tiers.
+func PropagateRequestID(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &propagate{base: base}
+}
+
+func (t *propagate) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := RequestID(req.Context()); id != "" && req.Header.Get(RequestIDHeader) == "" {
+		// Un RoundTripper ne doit pas modifier la requête reçue
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+	return t.base.RoundTrip(req)
+}
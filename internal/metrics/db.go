@@ -0,0 +1,28 @@
+package metrics
+
+import "database/sql"
+
+// DBStats expose les statistiques du pool de connexions db (sql.DB.Stats)
+func (r *Registry) DBStats(db *sql.DB) {
+	stat := func(name, help, kind string, value func(s sql.DBStats) float64) {
+		r.Collect(name, help, kind, nil, func() []Sample {
+			return []Sample{{Value: value(db.Stats())}}
+		})
+	}
+	stat("db_max_open_connections", "Nombre maximal de connexions ouvertes (0 : illimité)", "gauge",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	stat("db_open_connections", "Connexions ouvertes (utilisées et inactives)", "gauge",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	stat("db_in_use_connections", "Connexions en cours d'utilisation", "gauge",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	stat("db_idle_connections", "Connexions inactives", "gauge",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	stat("db_wait_count_total", "Attentes d'une connexion libre", "counter",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	stat("db_wait_duration_seconds_total", "Temps total passé à attendre une connexion libre", "counter",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	stat("db_max_idle_closed_total", "Connexions fermées car trop de connexions inactives", "counter",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	stat("db_max_lifetime_closed_total", "Connexions fermées car trop anciennes", "counter",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Métriques HTTP du serveur et des appels sortants
+var (
+	requestsTotal = Default.Counter("http_requests_total",
+		"Requêtes HTTP reçues, par route, méthode et statut", "route", "method", "status")
+	requestDuration = Default.Histogram("http_request_duration_seconds",
+		"Durée de traitement des requêtes HTTP, par route", nil, "route", "method")
+	upstreamTotal = Default.Counter("upstream_requests_total",
+		"Appels aux services externes, par service et code de réponse (\"error\" si aucune réponse)", "service", "code")
+	upstreamDuration = Default.Histogram("upstream_request_duration_seconds",
+		"Durée des appels aux services externes jusqu'aux en-têtes de réponse", nil, "service")
+)
+
+// Middleware compte les requêtes et mesure leur durée par route. La route
+// est le motif du ServeMux ("GET /api/artists/{id}/preview"), pas le chemin :
+// le nombre de séries reste borné.
+func Middleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			route = pattern
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		method := normalizeMethod(r.Method)
+		requestsTotal.Inc(route, method, strconv.Itoa(rec.status))
+		requestDuration.Observe(time.Since(start).Seconds(), route, method)
+	})
+}
+
+// RequireToken n'autorise que les requêtes portant "Authorization: Bearer <token>"
+// (comparaison en temps constant) ; les autres reçoivent 401
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Non autorisé", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder mémorise le statut de la réponse
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status, rec.wroteHeader = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap donne accès au ResponseWriter d'origine (http.ResponseController)
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// normalizeMethod évite une série par méthode inventée par un client
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
+
+// transport mesure les appels sortants vers un service externe
+type transport struct {
+	service string
+	base    http.RoundTripper
+}
+
+// Transport enveloppe base (http.DefaultTransport si nil) pour compter et
+// chronométrer les appels au service nommé ("groupie", "itunes", "deezer"...)
+func Transport(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{service: service, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	upstreamDuration.Observe(time.Since(start).Seconds(), t.service)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	upstreamTotal.Inc(t.service, code)
+	return resp, err
+}
@@ -0,0 +1,313 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets sont les bornes (en secondes) des histogrammes de latence
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default est le registre exposé par /metrics
+var Default = NewRegistry()
+
+// metric est une famille de séries écrite au format texte Prometheus
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry regroupe les métriques de l'application
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry crée un registre vide
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+	sort.Slice(r.metrics, func(i, j int) bool { return r.metrics[i].name() < r.metrics[j].name() })
+}
+
+// Handler sert les métriques au format texte Prometheus (version 0.0.4)
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		metrics := append([]metric(nil), r.metrics...)
+		r.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		bw := bufio.NewWriter(w)
+		for _, m := range metrics {
+			m.write(bw)
+		}
+		bw.Flush()
+	}
+}
+
+// desc contient le nom, l'aide et les noms de labels d'une famille
+type desc struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+}
+
+func (d *desc) name() string { return d.metricName }
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, d.kind)
+}
+
+// series est l'ensemble des valeurs de labels d'une famille, dans l'ordre d'apparition
+type series[T any] struct {
+	mu     sync.Mutex
+	keys   []string
+	values map[string][]string
+	data   map[string]T
+}
+
+// get retourne (en la créant si besoin) la donnée des valeurs de labels
+func (s *series[T]) get(labelValues []string, labels int, create func() T) T {
+	if len(labelValues) != labels {
+		panic(fmt.Sprintf("metrics: %d valeurs de labels pour %d labels", len(labelValues), labels))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if d, ok := s.data[key]; ok {
+		return d
+	}
+	if s.data == nil {
+		s.data = make(map[string]T)
+		s.values = make(map[string][]string)
+	}
+	d := create()
+	s.data[key] = d
+	s.values[key] = append([]string(nil), labelValues...)
+	s.keys = append(s.keys, key)
+	sort.Strings(s.keys)
+	return d
+}
+
+// Counter est un compteur croissant, éventuellement décliné par labels
+type Counter struct {
+	desc
+	series[*float64]
+}
+
+// Counter déclare un compteur
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{metricName: name, help: help, kind: "counter", labels: labels}}
+	r.register(c)
+	return c
+}
+
+// Inc ajoute 1 à la série des valeurs de labels données
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add ajoute v (positif) à la série des valeurs de labels données
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.get(labelValues, len(c.labels), func() *float64 { return new(float64) }) += v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.keys) == 0 {
+		return
+	}
+	c.writeHeader(w)
+	for _, key := range c.keys {
+		writeSample(w, c.metricName, c.labels, c.values[key], "", "", *c.data[key])
+	}
+}
+
+// Histogram répartit des observations (durées en secondes) dans des intervalles
+type Histogram struct {
+	desc
+	series[*histogramData]
+	buckets []float64
+}
+
+type histogramData struct {
+	counts []uint64 // par borne, non cumulés
+	count  uint64
+	sum    float64
+}
+
+// Histogram déclare un histogramme ; buckets nil donne DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{desc: desc{metricName: name, help: help, kind: "histogram", labels: labels}, buckets: buckets}
+	r.register(h)
+	return h
+}
+
+// Observe enregistre v dans la série des valeurs de labels données
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	d := h.get(labelValues, len(h.labels), func() *histogramData {
+		return &histogramData{counts: make([]uint64, len(h.buckets))}
+	})
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		d.counts[i]++
+	}
+	d.count++
+	d.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.keys) == 0 {
+		return
+	}
+	h.writeHeader(w)
+	for _, key := range h.keys {
+		d, values := h.data[key], h.values[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += d.counts[i]
+			writeSample(w, h.metricName+"_bucket", h.labels, values, "le", formatFloat(le), float64(cumulative))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, values, "le", "+Inf", float64(d.count))
+		writeSample(w, h.metricName+"_sum", h.labels, values, "", "", d.sum)
+		writeSample(w, h.metricName+"_count", h.labels, values, "", "", float64(d.count))
+	}
+}
+
+// Sample est une valeur lue au moment de la collecte
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// collected est une famille dont les valeurs sont lues à chaque collecte
+type collected struct {
+	desc
+	collect func() []Sample
+}
+
+// GaugeFunc déclare une jauge lue par fn à chaque collecte ; fn retourne
+// false si la valeur est indisponible (la série est alors omise)
+func (r *Registry) GaugeFunc(name, help string, fn func() (float64, bool)) {
+	r.Collect(name, help, "gauge", nil, func() []Sample {
+		if v, ok := fn(); ok {
+			return []Sample{{Value: v}}
+		}
+		return nil
+	})
+}
+
+// Collect déclare une famille (kind "gauge" ou "counter") dont les séries
+// sont produites par collect à chaque collecte (ex: statistiques d'un pool)
+func (r *Registry) Collect(name, help, kind string, labels []string, collect func() []Sample) {
+	r.register(&collected{desc: desc{metricName: name, help: help, kind: kind, labels: labels}, collect: collect})
+}
+
+func (c *collected) write(w *bufio.Writer) {
+	samples := c.collect()
+	if len(samples) == 0 {
+		return
+	}
+	c.writeHeader(w)
+	for _, s := range samples {
+		writeSample(w, c.metricName, c.labels, s.LabelValues, "", "", s.Value)
+	}
+}
+
+// Family décrit une famille sans label d'un groupe déclaré par CollectGroup
+type Family struct {
+	Name string
+	Help string
+	Kind string // "gauge" ou "counter"
+}
+
+// group est un ensemble de familles lues par un même appel
+type group struct {
+	families []Family
+	collect  func() ([]float64, bool)
+}
+
+// CollectGroup déclare des familles sans label lues ensemble par un seul
+// appel à collect à chaque collecte (ex: plusieurs COUNT d'une même requête
+// SQL). collect retourne une valeur par famille, dans l'ordre, ou false si
+// elles sont indisponibles (les familles sont alors omises).
+func (r *Registry) CollectGroup(families []Family, collect func() ([]float64, bool)) {
+	r.register(&group{families: families, collect: collect})
+}
+
+func (g *group) name() string { return g.families[0].Name }
+
+func (g *group) write(w *bufio.Writer) {
+	values, ok := g.collect()
+	if !ok || len(values) != len(g.families) {
+		return
+	}
+	for i, f := range g.families {
+		d := desc{metricName: f.Name, help: f.Help, kind: f.Kind}
+		d.writeHeader(w)
+		writeSample(w, f.Name, nil, nil, "", "", values[i])
+	}
+}
+
+// writeSample écrit une ligne `nom{label="valeur",...} valeur`
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
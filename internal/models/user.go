@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// User représente un compte utilisateur. L'identifiant de connexion
+// (id_utilisateur) est l'identifiant numérique attribué à l'inscription.
+type User struct {
+	ID           int       `json:"id_utilisateur"`
+	Nom          string    `json:"nom"`
+	Prenom       string    `json:"prenom"`
+	Sexe         string    `json:"sexe,omitempty"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
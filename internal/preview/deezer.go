@@ -0,0 +1,79 @@
+package preview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+)
+
+// DefaultDeezerURL est l'API publique Deezer
+const DefaultDeezerURL = "https://api.deezer.com"
+
+// Deezer cherche les extraits via l'API de recherche Deezer
+type Deezer struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewDeezer crée un fournisseur Deezer
+func NewDeezer(baseURL string) *Deezer {
+	if baseURL == "" {
+		baseURL = DefaultDeezerURL
+	}
+	return &Deezer{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("deezer", logging.Transport(nil))},
+	}
+}
+
+// Name retourne "deezer"
+func (d *Deezer) Name() string { return "deezer" }
+
+// Search interroge /search?q=artist:"<artiste>"
+func (d *Deezer) Search(ctx context.Context, artist string) ([]Track, error) {
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf("artist:%q", artist))
+	params.Set("limit", "10")
+
+	var body struct {
+		Data []struct {
+			Title   string `json:"title"`
+			Preview string `json:"preview"`
+			Artist  struct {
+				Name string `json:"name"`
+			} `json:"artist"`
+			Album struct {
+				Title       string `json:"title"`
+				CoverMedium string `json:"cover_medium"`
+			} `json:"album"`
+		} `json:"data"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := getJSON(ctx, d.httpClient, d.baseURL+"/search?"+params.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("preview: deezer: %w", err)
+	}
+	// Deezer signale ses erreurs (quota...) avec un code 200
+	if body.Error != nil {
+		return nil, fmt.Errorf("preview: deezer: %s", body.Error.Message)
+	}
+
+	tracks := make([]Track, 0, len(body.Data))
+	for _, r := range body.Data {
+		tracks = append(tracks, Track{
+			Artist:     r.Artist.Name,
+			Title:      r.Title,
+			Album:      r.Album.Title,
+			ArtworkURL: r.Album.CoverMedium,
+			PreviewURL: r.Preview,
+		})
+	}
+	return tracks, nil
+}
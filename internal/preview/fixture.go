@@ -0,0 +1,57 @@
+package preview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"groupiepersso/internal/search"
+)
+
+// Fixture est un fournisseur hors-ligne qui lit les morceaux dans un fichier JSON :
+//
+//	{"queen": [{"artist": "Queen", "title": "...", "preview_url": "https://..."}], ...}
+//
+// Les clés sont les noms d'artistes (accents et casse ignorés).
+// Utile en développement sans réseau et dans les tests.
+type Fixture struct {
+	tracks map[string][]Track
+}
+
+// NewFixture crée un fournisseur à partir d'une table en mémoire
+func NewFixture(tracks map[string][]Track) *Fixture {
+	normalized := make(map[string][]Track, len(tracks))
+	for name, t := range tracks {
+		key := search.Normalize(name)
+		normalized[key] = append(normalized[key], t...)
+	}
+	return &Fixture{tracks: normalized}
+}
+
+// LoadFixture lit un fichier de morceaux
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("preview: lecture fixtures: %w", err)
+	}
+	return ParseFixture(data)
+}
+
+// ParseFixture décode des morceaux au format de LoadFixture
+// (ex: fixtures embarquées dans le binaire)
+func ParseFixture(data []byte) (*Fixture, error) {
+	tracks := make(map[string][]Track)
+	if err := json.Unmarshal(data, &tracks); err != nil {
+		return nil, fmt.Errorf("preview: décodage fixtures: %w", err)
+	}
+	return NewFixture(tracks), nil
+}
+
+// Name retourne "fixture"
+func (f *Fixture) Name() string { return "fixture" }
+
+// Search retourne les morceaux enregistrés pour l'artiste
+func (f *Fixture) Search(ctx context.Context, artist string) ([]Track, error) {
+	return f.tracks[search.Normalize(artist)], nil
+}
@@ -0,0 +1,92 @@
+package preview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+)
+
+// DefaultITunesURL est l'API iTunes Search
+const DefaultITunesURL = "https://itunes.apple.com"
+
+// ITunes cherche les extraits via l'API iTunes Search
+type ITunes struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewITunes crée un fournisseur iTunes
+func NewITunes(baseURL string) *ITunes {
+	if baseURL == "" {
+		baseURL = DefaultITunesURL
+	}
+	return &ITunes{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("itunes", logging.Transport(nil))},
+	}
+}
+
+// Name retourne "itunes"
+func (i *ITunes) Name() string { return "itunes" }
+
+// Search interroge /search?term=<artiste>&entity=song
+func (i *ITunes) Search(ctx context.Context, artist string) ([]Track, error) {
+	params := url.Values{}
+	params.Set("term", artist)
+	params.Set("entity", "song")
+	params.Set("media", "music")
+	params.Set("attribute", "artistTerm")
+	params.Set("limit", "10")
+
+	var body struct {
+		Results []struct {
+			ArtistName     string `json:"artistName"`
+			TrackName      string `json:"trackName"`
+			CollectionName string `json:"collectionName"`
+			ArtworkURL100  string `json:"artworkUrl100"`
+			PreviewURL     string `json:"previewUrl"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, i.httpClient, i.baseURL+"/search?"+params.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("preview: itunes: %w", err)
+	}
+
+	tracks := make([]Track, 0, len(body.Results))
+	for _, r := range body.Results {
+		tracks = append(tracks, Track{
+			Artist:     r.ArtistName,
+			Title:      r.TrackName,
+			Album:      r.CollectionName,
+			ArtworkURL: r.ArtworkURL100,
+			PreviewURL: r.PreviewURL,
+		})
+	}
+	return tracks, nil
+}
+
+// getJSON décode la réponse JSON d'un GET
+func getJSON(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("réponse %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
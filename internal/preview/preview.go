@@ -0,0 +1,83 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"groupiepersso/internal/search"
+)
+
+// ErrNotFound est retourné quand aucun extrait ne correspond à l'artiste
+var ErrNotFound = errors.New("preview: aucun extrait trouvé")
+
+// Track est un morceau proposé par un fournisseur, avec son extrait de 30 s
+type Track struct {
+	Artist     string `json:"artist"`
+	Title      string `json:"title"`
+	Album      string `json:"album,omitempty"`
+	ArtworkURL string `json:"artwork_url,omitempty"`
+	PreviewURL string `json:"preview_url"`
+}
+
+// Provider cherche des morceaux d'un artiste (iTunes, Deezer, fixtures)
+type Provider interface {
+	Name() string
+	Search(ctx context.Context, artist string) ([]Track, error)
+}
+
+// Resolution est l'extrait retenu pour un artiste (ou son absence), conservé en cache
+type Resolution struct {
+	ArtistID  int       `json:"artist_id"`
+	Artist    string    `json:"artist"`
+	Found     bool      `json:"found"`
+	Provider  string    `json:"provider,omitempty"`
+	Track     Track     `json:"track"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BestMatch choisit le morceau dont l'artiste correspond le mieux à name
+// (accents et casse ignorés). Les morceaux d'autres artistes sont écartés ;
+// à score égal, l'ordre du fournisseur (pertinence) est conservé.
+func BestMatch(name string, tracks []Track) (Track, bool) {
+	want := search.Normalize(name)
+	best, bestScore := Track{}, 0
+	for _, t := range tracks {
+		if t.PreviewURL == "" {
+			continue
+		}
+		if score := matchScore(want, search.Normalize(t.Artist)); score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// matchScore note la correspondance entre deux noms normalisés :
+// 3 identiques, 2 l'un commence par l'autre ("queen" / "queen david bowie"),
+// 1 tous les mots de want présents, 0 sinon
+func matchScore(want, got string) int {
+	switch {
+	case want == "" || got == "":
+		return 0
+	case want == got:
+		return 3
+	case strings.HasPrefix(got, want+" ") || strings.HasPrefix(want, got+" "):
+		return 2
+	}
+	words := strings.Fields(got)
+	for _, w := range strings.Fields(want) {
+		found := false
+		for _, g := range words {
+			if g == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return 1
+}
@@ -0,0 +1,31 @@
+package preview
+
+import "testing"
+
+func TestBestMatch(t *testing.T) {
+	track := func(artist string) Track {
+		return Track{Artist: artist, Title: "Song", PreviewURL: "https://cdn.example/" + artist + ".mp3"}
+	}
+	tests := []struct {
+		name   string
+		artist string
+		tracks []Track
+		want   string
+		found  bool
+	}{
+		{"identique", "Queen", []Track{track("Queen & David Bowie"), track("Queen")}, "Queen", true},
+		{"accents et casse", "Beyoncé", []Track{track("BEYONCE")}, "BEYONCE", true},
+		{"préfixe", "Queen", []Track{track("Queen David Bowie")}, "Queen David Bowie", true},
+		{"tous les mots", "Pink Floyd", []Track{track("The Pink Floyd Band")}, "The Pink Floyd Band", true},
+		{"ordre du fournisseur à score égal", "Queen", []Track{track("Queen Latifah"), track("Queen David Bowie")}, "Queen Latifah", true},
+		{"autre artiste", "Queen", []Track{track("Queens of the Stone Age"), track("Freddie Mercury")}, "", false},
+		{"sans extrait", "Queen", []Track{{Artist: "Queen", Title: "Song"}}, "", false},
+		{"aucun morceau", "Queen", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, found := BestMatch(tt.artist, tt.tracks)
+		if found != tt.found || got.Artist != tt.want {
+			t.Errorf("%s: BestMatch = %q, %v ; attendu %q, %v", tt.name, got.Artist, found, tt.want, tt.found)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package preview
+
+import (
+	"context"
+	"time"
+
+	"groupiepersso/internal/logging"
+)
+
+// foundTTL est la durée de validité d'un extrait trouvé : les URL Deezer
+// sont signées et expirent
+const foundTTL = 24 * time.Hour
+
+// notFoundTTL est le délai avant de rechercher à nouveau un artiste sans extrait
+const notFoundTTL = 7 * 24 * time.Hour
+
+// Service résout l'extrait musical d'un artiste auprès des fournisseurs, dans
+// l'ordre, et garde le résultat dans le Store (PostgreSQL en production)
+type Service struct {
+	providers []Provider
+	store     Store
+}
+
+// NewService crée un service interrogeant providers dans l'ordre
+func NewService(store Store, providers ...Provider) *Service {
+	return &Service{providers: providers, store: store}
+}
+
+// Resolve retourne l'extrait de l'artiste, depuis le cache s'il est encore
+// valide. ErrNotFound si aucun fournisseur ne propose d'extrait.
+func (s *Service) Resolve(ctx context.Context, artistID int, name string) (Resolution, error) {
+	cached, ok, err := s.store.Get(ctx, artistID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Erreur lecture extrait", "artist_id", artistID, "err", err)
+	}
+	if ok && cached.Artist == name && !expired(cached) {
+		return result(cached)
+	}
+
+	res := Resolution{ArtistID: artistID, Artist: name, UpdatedAt: time.Now()}
+	var lastErr error
+	for _, p := range s.providers {
+		tracks, err := p.Search(ctx, name)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Recherche d'extrait échouée", "provider", p.Name(), "artist", name, "err", err)
+			lastErr = err
+			continue
+		}
+		if track, found := BestMatch(name, tracks); found {
+			res.Found, res.Provider, res.Track = true, p.Name(), track
+			break
+		}
+	}
+
+	// Tous les fournisseurs en erreur : ne pas mémoriser une absence
+	// d'extrait, et préférer l'ancien résultat s'il existe
+	if !res.Found && lastErr != nil {
+		if ok {
+			return result(cached)
+		}
+		return Resolution{}, lastErr
+	}
+
+	if err := s.store.Put(ctx, res); err != nil {
+		logging.FromContext(ctx).Warn("Erreur sauvegarde extrait", "artist_id", artistID, "err", err)
+	}
+	return result(res)
+}
+
+// expired indique si un résultat en cache doit être recherché à nouveau
+func expired(res Resolution) bool {
+	ttl := foundTTL
+	if !res.Found {
+		ttl = notFoundTTL
+	}
+	return time.Since(res.UpdatedAt) > ttl
+}
+
+// result convertit une absence d'extrait en ErrNotFound
+func result(res Resolution) (Resolution, error) {
+	if !res.Found {
+		return res, ErrNotFound
+	}
+	return res, nil
+}
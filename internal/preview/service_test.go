@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// counting compte les recherches d'un fournisseur ; err simule une panne
+type counting struct {
+	Provider
+	calls int
+	err   error
+}
+
+func (c *counting) Search(ctx context.Context, artist string) ([]Track, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Provider.Search(ctx, artist)
+}
+
+func newFixture() *counting {
+	return &counting{Provider: NewFixture(map[string][]Track{
+		"queen": {{Artist: "Queen", Title: "Bohemian Rhapsody", PreviewURL: "https://cdn.example/queen.mp3"}},
+	})}
+}
+
+func TestServiceResolve(t *testing.T) {
+	ctx := context.Background()
+	provider := newFixture()
+	s := NewService(NewMemoryStore(), provider)
+
+	res, err := s.Resolve(ctx, 1, "Queen")
+	if err != nil || !res.Found || res.Provider != "fixture" || res.Track.Title != "Bohemian Rhapsody" {
+		t.Fatalf("Resolve = %+v, %v", res, err)
+	}
+	if _, err := s.Resolve(ctx, 1, "Queen"); err != nil || provider.calls != 1 {
+		t.Fatalf("second Resolve: %v, %d recherches ; attendu le cache", err, provider.calls)
+	}
+
+	// Le nom de l'artiste a changé : nouvelle recherche
+	if _, err := s.Resolve(ctx, 1, "Queen II"); !errors.Is(err, ErrNotFound) || provider.calls != 2 {
+		t.Fatalf("Resolve après renommage: %v, %d recherches", err, provider.calls)
+	}
+}
+
+func TestServiceResolveTTL(t *testing.T) {
+	ctx := context.Background()
+	provider := newFixture()
+	store := NewMemoryStore()
+	s := NewService(store, provider)
+
+	tests := []struct {
+		name     string
+		cached   Resolution
+		searched bool
+	}{
+		{"extrait récent", Resolution{ArtistID: 1, Artist: "Queen", Found: true, UpdatedAt: time.Now().Add(-time.Hour)}, false},
+		{"extrait expiré", Resolution{ArtistID: 1, Artist: "Queen", Found: true, UpdatedAt: time.Now().Add(-foundTTL - time.Hour)}, true},
+		{"absence récente", Resolution{ArtistID: 1, Artist: "Queen", UpdatedAt: time.Now().Add(-foundTTL - time.Hour)}, false},
+		{"absence expirée", Resolution{ArtistID: 1, Artist: "Queen", UpdatedAt: time.Now().Add(-notFoundTTL - time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		store.Put(ctx, tt.cached)
+		before := provider.calls
+		_, err := s.Resolve(ctx, 1, "Queen")
+		if searched := provider.calls > before; searched != tt.searched {
+			t.Errorf("%s: recherche = %v, attendu %v", tt.name, searched, tt.searched)
+		}
+		// Une absence encore valide est servie depuis le cache négatif
+		if !tt.cached.Found && !tt.searched && !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: err = %v, attendu ErrNotFound", tt.name, err)
+		}
+	}
+}
+
+func TestServiceResolveProviderErrors(t *testing.T) {
+	ctx := context.Background()
+	down := &counting{Provider: NewFixture(nil), err: errors.New("quota dépassé")}
+	store := NewMemoryStore()
+
+	// Fournisseur en panne : le suivant est interrogé
+	fallback := newFixture()
+	if res, err := NewService(store, down, fallback).Resolve(ctx, 1, "Queen"); err != nil || !res.Found {
+		t.Fatalf("Resolve avec repli = %+v, %v", res, err)
+	}
+
+	// Tous les fournisseurs en panne : l'absence n'est pas mémorisée
+	s := NewService(store, down)
+	if _, err := s.Resolve(ctx, 2, "Freddie Mercury"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Resolve en panne: err = %v, attendu l'erreur du fournisseur", err)
+	}
+	if _, ok, _ := store.Get(ctx, 2); ok {
+		t.Error("absence mémorisée alors que les fournisseurs sont en panne")
+	}
+
+	// ... et l'ancien résultat expiré est préféré
+	old := Resolution{ArtistID: 1, Artist: "Queen", Found: true, Provider: "fixture", UpdatedAt: time.Now().Add(-foundTTL - time.Hour)}
+	store.Put(ctx, old)
+	if res, err := s.Resolve(ctx, 1, "Queen"); err != nil || !res.Found {
+		t.Errorf("Resolve en panne avec cache expiré = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,93 @@
+package preview
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Store conserve les extraits déjà résolus
+type Store interface {
+	Get(ctx context.Context, artistID int) (Resolution, bool, error)
+	Put(ctx context.Context, res Resolution) error
+}
+
+// PostgresStore stocke les extraits dans la table artist_previews
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore crée un store adossé à db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Get retourne l'extrait résolu pour un artiste
+func (s *PostgresStore) Get(ctx context.Context, artistID int) (Resolution, bool, error) {
+	res := Resolution{ArtistID: artistID}
+	var provider, title, album, artwork, previewURL sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT artist_name, found, provider, track_artist, track_title, album, artwork_url, preview_url, updated_at
+		FROM artist_previews
+		WHERE artist_id = $1
+	`, artistID).Scan(&res.Artist, &res.Found, &provider, &res.Track.Artist, &title, &album, &artwork, &previewURL, &res.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Resolution{}, false, nil
+	}
+	if err != nil {
+		return Resolution{}, false, err
+	}
+	res.Provider = provider.String
+	res.Track.Title = title.String
+	res.Track.Album = album.String
+	res.Track.ArtworkURL = artwork.String
+	res.Track.PreviewURL = previewURL.String
+	return res, true, nil
+}
+
+// Put insère ou met à jour l'extrait d'un artiste
+func (s *PostgresStore) Put(ctx context.Context, res Resolution) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO artist_previews (artist_id, artist_name, found, provider, track_artist, track_title, album, artwork_url, preview_url, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (artist_id) DO UPDATE SET
+			artist_name = EXCLUDED.artist_name,
+			found = EXCLUDED.found,
+			provider = EXCLUDED.provider,
+			track_artist = EXCLUDED.track_artist,
+			track_title = EXCLUDED.track_title,
+			album = EXCLUDED.album,
+			artwork_url = EXCLUDED.artwork_url,
+			preview_url = EXCLUDED.preview_url,
+			updated_at = EXCLUDED.updated_at
+	`, res.ArtistID, res.Artist, res.Found, res.Provider, res.Track.Artist, res.Track.Title, res.Track.Album, res.Track.ArtworkURL, res.Track.PreviewURL, res.UpdatedAt)
+	return err
+}
+
+// MemoryStore garde les extraits en mémoire (sans base de données)
+type MemoryStore struct {
+	mu      sync.Mutex
+	results map[int]Resolution
+}
+
+// NewMemoryStore crée un store vide
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{results: make(map[int]Resolution)}
+}
+
+// Get retourne l'extrait résolu pour un artiste
+func (s *MemoryStore) Get(ctx context.Context, artistID int) (Resolution, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, ok := s.results[artistID]
+	return res, ok, nil
+}
+
+// Put insère ou met à jour l'extrait d'un artiste
+func (s *MemoryStore) Put(ctx context.Context, res Resolution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[res.ArtistID] = res
+	return nil
+}
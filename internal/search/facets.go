@@ -0,0 +1,99 @@
+package search
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Dimensions de facettes
+const (
+	facetMembers         = "members"
+	facetCreationDecades = "creation_decades"
+	facetAlbumDecades    = "album_decades"
+	facetCountries       = "countries"
+)
+
+// FacetValue est une valeur de facette avec le nombre d'artistes correspondants
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets regroupe les comptages par facette pour la requête courante
+type Facets struct {
+	Members         []FacetValue `json:"members"`
+	CreationDecades []FacetValue `json:"creation_decades"`
+	AlbumDecades    []FacetValue `json:"album_decades"`
+	Countries       []FacetValue `json:"countries"`
+}
+
+// computeFacets compte, parmi les documents correspondant au texte recherché,
+// les artistes par valeur de chaque facette. Chaque facette est calculée avec
+// tous les filtres sauf le sien, afin que l'on voie combien d'artistes
+// obtiendrait chaque autre choix ("4 membres (12)", "USA (9)", "1970s (5)").
+func computeFacets(docs []*document, f *Filter) Facets {
+	return Facets{
+		Members: countFacet(docs, f.without(facetMembers), func(d *document) []string {
+			return []string{strconv.Itoa(len(d.entry.Artist.Members))}
+		}, byNumber),
+		CreationDecades: countFacet(docs, f.without(facetCreationDecades), func(d *document) []string {
+			return []string{decadeLabel(d.entry.Artist.CreationDate)}
+		}, byNumber),
+		AlbumDecades: countFacet(docs, f.without(facetAlbumDecades), func(d *document) []string {
+			if d.albumDate.IsZero() {
+				return nil
+			}
+			return []string{decadeLabel(d.albumDate.Year())}
+		}, byNumber),
+		Countries: countFacet(docs, f.without(facetCountries), func(d *document) []string {
+			return d.countries
+		}, byCount),
+	}
+}
+
+// countFacet compte les valeurs retournées par values pour les documents acceptés par f
+func countFacet(docs []*document, f Filter, values func(d *document) []string, less func(a, b FacetValue) bool) []FacetValue {
+	counts := make(map[string]int)
+	for _, d := range docs {
+		if !f.Match(d) {
+			continue
+		}
+		for _, v := range values(d) {
+			counts[v]++
+		}
+	}
+
+	out := make([]FacetValue, 0, len(counts))
+	for v, n := range counts {
+		out = append(out, FacetValue{Value: v, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
+
+// byNumber trie par valeur numérique croissante ("4" < "12", "1970s" < "1980s")
+func byNumber(a, b FacetValue) bool {
+	x, _ := strconv.Atoi(trimDecade(a.Value))
+	y, _ := strconv.Atoi(trimDecade(b.Value))
+	return x < y
+}
+
+// byCount trie par nombre d'artistes décroissant puis par nom
+func byCount(a, b FacetValue) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Value < b.Value
+}
+
+// decadeLabel formate une décennie : 1973 -> "1970s"
+func decadeLabel(year int) string {
+	return strconv.Itoa(decade(year)) + "s"
+}
+
+func trimDecade(s string) string {
+	if n := len(s); n > 0 && s[n-1] == 's' {
+		return s[:n-1]
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter regroupe les filtres combinables (ET logique entre filtres,
+// OU logique entre les valeurs d'un même filtre)
+type Filter struct {
+	CreationFrom int
+	CreationTo   int
+	AlbumFrom    time.Time
+	AlbumTo      time.Time
+	Members      []int
+	Countries    []string
+	Cities       []string
+
+	CreationDecades []int
+	AlbumDecades    []int
+}
+
+// ParseFilter lit les filtres depuis l'URL :
+//
+//	creation_from, creation_to : années de création (ex: 1970)
+//	album_from, album_to       : année ou date ISO du premier album (ex: 1973 ou 1973-12-14)
+//	members                    : nombres de membres (ex: members=1,4 ou members=1&members=4)
+//	country, city              : pays/ville de concert (ex: country=usa, city=north_carolina)
+//	creation_decade            : décennies de création (ex: creation_decade=1970s,1980s)
+//	album_decade               : décennies du premier album (ex: album_decade=1990s)
+func ParseFilter(v url.Values) (Filter, error) {
+	var f Filter
+	var err error
+
+	if f.CreationFrom, err = parseYear(v.Get("creation_from")); err != nil {
+		return f, fmt.Errorf("creation_from invalide: %v", err)
+	}
+	if f.CreationTo, err = parseYear(v.Get("creation_to")); err != nil {
+		return f, fmt.Errorf("creation_to invalide: %v", err)
+	}
+	if f.AlbumFrom, err = parseAlbumBound(v.Get("album_from"), false); err != nil {
+		return f, fmt.Errorf("album_from invalide: %v", err)
+	}
+	if f.AlbumTo, err = parseAlbumBound(v.Get("album_to"), true); err != nil {
+		return f, fmt.Errorf("album_to invalide: %v", err)
+	}
+
+	for _, s := range splitValues(v["members"]) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return f, fmt.Errorf("members invalide: %q", s)
+		}
+		f.Members = append(f.Members, n)
+	}
+	for _, s := range splitValues(v["country"]) {
+		f.Countries = append(f.Countries, Normalize(s))
+	}
+	for _, s := range splitValues(v["city"]) {
+		f.Cities = append(f.Cities, Normalize(s))
+	}
+	if f.CreationDecades, err = parseDecades(v["creation_decade"]); err != nil {
+		return f, fmt.Errorf("creation_decade invalide: %v", err)
+	}
+	if f.AlbumDecades, err = parseDecades(v["album_decade"]); err != nil {
+		return f, fmt.Errorf("album_decade invalide: %v", err)
+	}
+	return f, nil
+}
+
+// Match indique si le document satisfait tous les filtres
+func (f *Filter) Match(d *document) bool {
+	a := d.entry.Artist
+	if f.CreationFrom != 0 && a.CreationDate < f.CreationFrom {
+		return false
+	}
+	if f.CreationTo != 0 && a.CreationDate > f.CreationTo {
+		return false
+	}
+	if !f.AlbumFrom.IsZero() && (d.albumDate.IsZero() || d.albumDate.Before(f.AlbumFrom)) {
+		return false
+	}
+	if !f.AlbumTo.IsZero() && (d.albumDate.IsZero() || d.albumDate.After(f.AlbumTo)) {
+		return false
+	}
+	if len(f.Members) > 0 && !slices.Contains(f.Members, len(a.Members)) {
+		return false
+	}
+	if (len(f.Countries) > 0 || len(f.Cities) > 0) && !f.matchPlace(d) {
+		return false
+	}
+	if len(f.CreationDecades) > 0 && !slices.Contains(f.CreationDecades, decade(a.CreationDate)) {
+		return false
+	}
+	if len(f.AlbumDecades) > 0 && (d.albumDate.IsZero() || !slices.Contains(f.AlbumDecades, decade(d.albumDate.Year()))) {
+		return false
+	}
+	return true
+}
+
+// matchPlace indique si un même lieu de concert satisfait à la fois le
+// filtre de pays et celui de ville (country=usa&city=london ne retient pas
+// un artiste passé par london-uk et new_york-usa)
+func (f *Filter) matchPlace(d *document) bool {
+	for _, p := range d.places {
+		if (len(f.Countries) == 0 || slices.Contains(f.Countries, p.country)) &&
+			(len(f.Cities) == 0 || slices.Contains(f.Cities, p.city)) {
+			return true
+		}
+	}
+	return false
+}
+
+// without retourne une copie du filtre sans la dimension de facette donnée,
+// pour compter les autres valeurs possibles de cette facette
+func (f Filter) without(facet string) Filter {
+	switch facet {
+	case facetMembers:
+		f.Members = nil
+	case facetCreationDecades:
+		f.CreationDecades = nil
+	case facetAlbumDecades:
+		f.AlbumDecades = nil
+	case facetCountries:
+		f.Countries = nil
+	}
+	return f
+}
+
+// parseYear lit une année, 0 si vide
+func parseYear(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimSpace(s))
+}
+
+// parseAlbumBound lit une borne de date : "1973" ou "1973-12-14".
+// Une année seule couvre toute l'année (1er janvier ou 31 décembre selon la borne).
+func parseAlbumBound(s string, upper bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if year, err := strconv.Atoi(s); err == nil {
+		if upper {
+			return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), nil
+		}
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// parseDecades lit des décennies au format "1970s" ou "1970"
+func parseDecades(values []string) ([]int, error) {
+	var out []int
+	for _, s := range splitValues(values) {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "s"))
+		if err != nil || n%10 != 0 {
+			return nil, fmt.Errorf("%q", s)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// decade retourne la décennie d'une année (1973 -> 1970)
+func decade(year int) int {
+	return year - year%10
+}
+
+// splitValues accepte les valeurs répétées et séparées par des virgules
+func splitValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
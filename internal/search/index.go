@@ -0,0 +1,105 @@
+package search
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"groupiepersso/internal/catalog"
+	"groupiepersso/internal/groupie"
+)
+
+// document contient les champs normalisés d'un artiste, calculés une seule fois
+type document struct {
+	entry      *catalog.Entry
+	name       string
+	members    []string
+	locations  []string
+	creation   string
+	firstAlbum string
+	albumDate  time.Time
+	cities     []string
+	countries  []string
+	places     []place
+}
+
+// place est un lieu de concert (ville et pays normalisés d'un même slug)
+type place struct {
+	city, country string
+}
+
+// Index est la structure de recherche construite à partir d'un snapshot du catalogue
+type Index struct {
+	version  uint64
+	docs     []document
+	prefixes prefixIndex
+}
+
+// NewIndex construit l'index de recherche d'un snapshot
+func NewIndex(snap *catalog.Snapshot) *Index {
+	idx := &Index{version: snap.Version, docs: make([]document, 0, len(snap.Entries))}
+	for i := range snap.Entries {
+		e := &snap.Entries[i]
+		d := document{
+			entry:      e,
+			name:       Normalize(e.Artist.Name),
+			creation:   strconv.Itoa(e.Artist.CreationDate),
+			firstAlbum: Normalize(e.Artist.FirstAlbum),
+		}
+		for _, m := range e.Artist.Members {
+			d.members = append(d.members, Normalize(m))
+		}
+		if t, err := groupie.ParseDate(e.Artist.FirstAlbum); err == nil {
+			d.albumDate = t
+		}
+		for _, l := range e.Locations {
+			d.locations = append(d.locations, Normalize(l))
+			city, country := groupie.ParseLocation(l)
+			p := place{city: Normalize(city), country: Normalize(country)}
+			d.cities = appendUnique(d.cities, p.city)
+			d.countries = appendUnique(d.countries, p.country)
+			d.places = append(d.places, p)
+		}
+		idx.docs = append(idx.docs, d)
+	}
+	idx.prefixes = buildPrefixIndex(idx.docs)
+	return idx
+}
+
+// Engine fournit un index toujours aligné sur la dernière version du catalogue
+type Engine struct {
+	cat *catalog.Catalog
+
+	mu  sync.Mutex
+	idx *Index
+}
+
+// NewEngine crée un moteur de recherche adossé au catalogue
+func NewEngine(cat *catalog.Catalog) *Engine {
+	return &Engine{cat: cat}
+}
+
+// Index retourne l'index du snapshot courant (reconstruit si le catalogue a changé),
+// ou nil si le catalogue n'est pas encore chargé
+func (e *Engine) Index() *Index {
+	snap := e.cat.Snapshot()
+	if snap == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.idx == nil || e.idx.version != snap.Version {
+		e.idx = NewIndex(snap)
+	}
+	return e.idx
+}
+
+// appendUnique ajoute s à list s'il n'y est pas déjà
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"groupiepersso/internal/groupie"
+)
+
+// Champs sur lesquels une recherche peut correspondre
+const (
+	FieldArtist     = "artist"
+	FieldMember     = "member"
+	FieldLocation   = "location"
+	FieldCreation   = "creation_date"
+	FieldFirstAlbum = "first_album"
+)
+
+// Limites de pagination
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Poids de chaque champ selon la qualité de la correspondance (exacte, préfixe, contenu)
+var weights = map[string][3]int{
+	FieldArtist:     {100, 80, 60},
+	FieldMember:     {70, 50, 40},
+	FieldLocation:   {45, 35, 30},
+	FieldCreation:   {25, 0, 0},
+	FieldFirstAlbum: {20, 20, 15},
+}
+
+// Match indique quel champ d'un artiste correspond à la recherche
+type Match struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// Hit est un artiste trouvé avec son score et ses correspondances
+type Hit struct {
+	Artist  groupie.Artist `json:"artist"`
+	Score   int            `json:"score"`
+	Matches []Match        `json:"matches"`
+}
+
+// Query décrit une recherche paginée, éventuellement filtrée
+type Query struct {
+	Text   string
+	Filter Filter
+	Page   int
+	Limit  int
+}
+
+// Results est une page de résultats
+type Results struct {
+	Query   string `json:"query"`
+	Total   int    `json:"total"`
+	Page    int    `json:"page"`
+	Limit   int    `json:"limit"`
+	Results []Hit  `json:"results"`
+	Facets  Facets `json:"facets"`
+}
+
+// Search cherche q dans le nom, les membres, les lieux de concerts,
+// l'année de création et la date du premier album, applique les filtres,
+// puis classe les résultats et compte les facettes
+func (idx *Index) Search(q Query) Results {
+	page, limit := q.Page, q.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	hits, docs := idx.match(Normalize(q.Text))
+	hits = applyFilter(hits, docs, &q.Filter)
+	res := Results{
+		Query:   q.Text,
+		Total:   len(hits),
+		Page:    page,
+		Limit:   limit,
+		Results: []Hit{},
+		Facets:  computeFacets(docs, &q.Filter),
+	}
+
+	// Comparer en nombre de pages évite le dépassement de (page-1)*limit
+	// pour une page démesurée (?page=9223372036854775807)
+	if pages := (len(hits) + limit - 1) / limit; page-1 < pages {
+		start := (page - 1) * limit
+		end := start + limit
+		if end > len(hits) {
+			end = len(hits)
+		}
+		res.Results = hits[start:end]
+	}
+	return res
+}
+
+// match retourne les artistes correspondant à la requête normalisée (avant filtres),
+// triés par score, ainsi que les documents correspondants dans le même ordre
+func (idx *Index) match(q string) ([]Hit, []*document) {
+	type scored struct {
+		hit Hit
+		doc *document
+	}
+
+	found := make([]scored, 0, len(idx.docs))
+	for i := range idx.docs {
+		d := &idx.docs[i]
+		if q == "" {
+			found = append(found, scored{Hit{Artist: d.entry.Artist, Matches: []Match{}}, d})
+			continue
+		}
+		if hit, ok := d.match(q); ok {
+			found = append(found, scored{hit, d})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i].hit, found[j].hit
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return strings.ToLower(a.Artist.Name) < strings.ToLower(b.Artist.Name)
+	})
+
+	hits := make([]Hit, len(found))
+	docs := make([]*document, len(found))
+	for i, f := range found {
+		hits[i], docs[i] = f.hit, f.doc
+	}
+	return hits, docs
+}
+
+// applyFilter ne garde que les résultats dont le document satisfait le filtre
+func applyFilter(hits []Hit, docs []*document, f *Filter) []Hit {
+	out := make([]Hit, 0, len(hits))
+	for i, d := range docs {
+		if f.Match(d) {
+			out = append(out, hits[i])
+		}
+	}
+	return out
+}
+
+// match teste chaque champ du document ; le score est celui de la meilleure
+// correspondance, plus un petit bonus par champ supplémentaire
+func (d *document) match(q string) (Hit, bool) {
+	hit := Hit{Artist: d.entry.Artist}
+	best, extra := 0, 0
+
+	try := func(field, normalized, original string) {
+		score := scoreOf(field, normalized, q)
+		if score == 0 {
+			return
+		}
+		hit.Matches = append(hit.Matches, Match{Field: field, Value: original})
+		if score > best {
+			extra += best / 10
+			best = score
+		} else {
+			extra += score / 10
+		}
+	}
+
+	try(FieldArtist, d.name, d.entry.Artist.Name)
+	for i, m := range d.members {
+		try(FieldMember, m, d.entry.Artist.Members[i])
+	}
+	for i, l := range d.locations {
+		try(FieldLocation, l, d.entry.Locations[i])
+	}
+	try(FieldCreation, d.creation, d.creation)
+	try(FieldFirstAlbum, d.firstAlbum, d.entry.Artist.FirstAlbum)
+
+	if best == 0 {
+		return Hit{}, false
+	}
+	hit.Score = best + extra
+	return hit, true
+}
+
+// scoreOf note la correspondance de q dans value : exacte, préfixe d'un mot, ou contenu
+func scoreOf(field, value, q string) int {
+	w := weights[field]
+	switch {
+	case value == q:
+		return w[0]
+	case strings.HasPrefix(value, q) || strings.Contains(value, " "+q):
+		return w[1]
+	case strings.Contains(value, q):
+		return w[2]
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// Types de suggestions
+const (
+	SuggestArtist   = "artist/band"
+	SuggestMember   = "member"
+	SuggestLocation = "location"
+)
+
+// Limites du nombre de suggestions
+const (
+	DefaultSuggestLimit = 8
+	MaxSuggestLimit     = 20
+)
+
+// priorité d'affichage des types à pertinence égale
+var suggestPriority = map[string]int{
+	SuggestArtist:   0,
+	SuggestMember:   1,
+	SuggestLocation: 2,
+}
+
+// Suggestion est une proposition d'autocomplétion, ex: "Freddie Mercury — member"
+type Suggestion struct {
+	Text     string `json:"text"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	ArtistID int    `json:"artist_id,omitempty"`
+}
+
+// prefixEntry associe une clé normalisée à une suggestion. whole vaut true
+// si la clé est le texte complet (et non un mot intérieur, ex: "mercury")
+type prefixEntry struct {
+	key   string
+	whole bool
+	sugg  *Suggestion
+}
+
+// prefixIndex est une tranche triée par clé, interrogée par recherche dichotomique
+type prefixIndex []prefixEntry
+
+// buildPrefixIndex indexe les noms d'artistes, membres et lieux sur chacun de leurs mots
+func buildPrefixIndex(docs []document) prefixIndex {
+	var idx prefixIndex
+	seen := make(map[string]bool)
+
+	add := func(normalized string, s *Suggestion) {
+		id := s.Type + "\x00" + normalized
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		s.Label = s.Text + " — " + s.Type
+
+		idx = append(idx, prefixEntry{key: normalized, whole: true, sugg: s})
+		for i := 0; i < len(normalized); i++ {
+			if normalized[i] == ' ' && i+1 < len(normalized) {
+				idx = append(idx, prefixEntry{key: normalized[i+1:], sugg: s})
+			}
+		}
+	}
+
+	for i := range docs {
+		d := &docs[i]
+		a := d.entry.Artist
+		add(d.name, &Suggestion{Text: a.Name, Type: SuggestArtist, ArtistID: a.ID})
+		for j, m := range d.members {
+			add(m, &Suggestion{Text: a.Members[j], Type: SuggestMember, ArtistID: a.ID})
+		}
+		for j, l := range d.locations {
+			add(l, &Suggestion{Text: d.entry.Locations[j], Type: SuggestLocation})
+		}
+	}
+
+	sort.Slice(idx, func(i, j int) bool { return idx[i].key < idx[j].key })
+	return idx
+}
+
+// Suggest retourne au plus limit suggestions dont un mot commence par q
+// (insensible à la casse et aux accents)
+func (idx *Index) Suggest(q string, limit int) []Suggestion {
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	out := []Suggestion{}
+	q = Normalize(q)
+	if q == "" {
+		return out
+	}
+
+	var found []prefixEntry
+	seen := make(map[*Suggestion]int)
+
+	start := sort.Search(len(idx.prefixes), func(i int) bool { return idx.prefixes[i].key >= q })
+	for i := start; i < len(idx.prefixes) && strings.HasPrefix(idx.prefixes[i].key, q); i++ {
+		e := idx.prefixes[i]
+		if pos, ok := seen[e.sugg]; ok {
+			if e.whole {
+				found[pos].whole = true
+			}
+			continue
+		}
+		seen[e.sugg] = len(found)
+		found = append(found, e)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.whole != b.whole {
+			return a.whole
+		}
+		if pa, pb := suggestPriority[a.sugg.Type], suggestPriority[b.sugg.Type]; pa != pb {
+			return pa < pb
+		}
+		return a.sugg.Text < b.sugg.Text
+	})
+
+	for _, e := range found {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, *e.sugg)
+	}
+	return out
+}
@@ -1,42 +1,179 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"groupiepersso/internal/assets"
+	"groupiepersso/internal/audio"
+	"groupiepersso/internal/auth"
+	"groupiepersso/internal/catalog"
 	"groupiepersso/internal/core"
+	"groupiepersso/internal/cors"
 	"groupiepersso/internal/database"
+	"groupiepersso/internal/favorites"
+	"groupiepersso/internal/geo"
+	"groupiepersso/internal/groupie"
 	"groupiepersso/internal/handlers"
+	"groupiepersso/internal/health"
+	"groupiepersso/internal/logging"
+	"groupiepersso/internal/metrics"
+	"groupiepersso/internal/preview"
+	"groupiepersso/internal/search"
 )
 
-// proxyAPI fait un proxy HTTP simple vers une URL cible
-func proxyAPI(targetURL string) http.HandlerFunc {
+// dbStartupAttempts est le nombre de connexions tentées au démarrage quand
+// la base est requise (2 s, 4 s, 8 s, 16 s d'attente entre les essais)
+const dbStartupAttempts = 5
+
+// proxyCatalog sert au format JSON une vue du catalogue en mémoire,
+// sans jamais attendre l'API Groupie Trackers
+func proxyCatalog(cat *catalog.Catalog, view func(s *catalog.Snapshot) interface{}) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Créer une requête GET vers l'API distante
-		resp, err := http.Get(targetURL)
-		if err != nil {
-			http.Error(w, "API unavailable", http.StatusServiceUnavailable)
+		snap := cat.Snapshot()
+		w.Header().Set("Content-Type", "application/json")
+
+		if snap == nil {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": "API unavailable"})
 			return
 		}
-		defer resp.Body.Close()
 
-		// Copier les headers de la réponse API
-		for key, values := range resp.Header {
-			for _, value := range values {
-				w.Header().Add(key, value)
+		w.Header().Set("Last-Modified", snap.UpdatedAt.UTC().Format(http.TimeFormat))
+		json.NewEncoder(w).Encode(view(snap))
+	}
+}
+
+// newHealthChecker déclare les dépendances vérifiées par /readyz ; celles de
+// cfg.ReadyRequired rendent le serveur non prêt quand elles échouent
+func newHealthChecker(cfg *core.Config, client *groupie.Client, cat *catalog.Catalog) *health.Checker {
+	checker := health.New(cfg.ReadyRequired, health.DefaultTimeout)
+	checker.Add("database", func(ctx context.Context) error {
+		if database.DB == nil {
+			return errors.New("base de données non connectée")
+		}
+		return database.DB.PingContext(ctx)
+	})
+	// L'API distante est lente à se réveiller : pas plus d'un appel par 30 s
+	checker.Add("groupie", health.Cached(30*time.Second, client.Ping))
+	checker.Add("catalog", func(ctx context.Context) error {
+		st := cat.Status()
+		switch {
+		case !st.Ready:
+			return errors.New("catalogue pas encore chargé")
+		case st.AgeSeconds > cfg.CatalogMaxAge.Seconds():
+			err := fmt.Errorf("catalogue non rafraîchi depuis %s", time.Since(st.LastRefresh).Round(time.Second))
+			if st.LastError != "" {
+				err = fmt.Errorf("%v: %s", err, st.LastError)
 			}
+			return err
 		}
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Content-Type", "application/json")
+		return nil
+	})
+	if unknown := checker.Unknown(); len(unknown) > 0 {
+		slog.Warn("READY_REQUIRED: dépendances inconnues ignorées", "unknown", unknown)
+	}
+	return checker
+}
+
+// registerMetrics expose dans /metrics l'état du catalogue, des favoris et
+// du pool de connexions PostgreSQL (lus à chaque collecte)
+func registerMetrics(cat *catalog.Catalog, store favorites.Store) {
+	metrics.Default.GaugeFunc("catalog_age_seconds", "Âge des données du catalogue Groupie Trackers", func() (float64, bool) {
+		st := cat.Status()
+		return st.AgeSeconds, st.Ready
+	})
+	metrics.Default.GaugeFunc("catalog_artists", "Artistes présents dans le catalogue", func() (float64, bool) {
+		return float64(cat.Status().Artists), true
+	})
+	metrics.Default.GaugeFunc("catalog_healthy", "1 si le dernier rafraîchissement du catalogue a réussi", func() (float64, bool) {
+		if cat.Status().Healthy {
+			return 1, true
+		}
+		return 0, true
+	})
+
+	// Une seule requête de comptage par collecte pour les deux familles
+	metrics.Default.CollectGroup([]metrics.Family{
+		{Name: "favorites", Help: "Favoris enregistrés (tous utilisateurs)", Kind: "gauge"},
+		{Name: "favorites_users", Help: "Utilisateurs ayant au moins un favori", Kind: "gauge"},
+	}, func() ([]float64, bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		st, err := store.Stats(ctx)
+		if err != nil {
+			slog.Warn("Lecture des statistiques de favoris échouée", "err", err)
+			return nil, false
+		}
+		return []float64{float64(st.Favorites), float64(st.Users)}, true
+	})
+
+	if database.DB != nil {
+		metrics.Default.DBStats(database.DB)
+	}
+}
 
-		// Copier le statut et le body
-		w.WriteHeader(resp.StatusCode)
-		io.Copy(w, resp.Body)
+// newPreviewProviders construit les fournisseurs d'extraits listés dans cfg.PreviewProviders
+func newPreviewProviders(cfg *core.Config) []preview.Provider {
+	var providers []preview.Provider
+	for _, name := range strings.Split(cfg.PreviewProviders, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "itunes":
+			providers = append(providers, preview.NewITunes(cfg.ITunesURL))
+		case "deezer":
+			providers = append(providers, preview.NewDeezer(cfg.DeezerURL))
+		case "fixture":
+			data, err := readFixture(cfg.PreviewFixtures)
+			if err != nil {
+				slog.Error("Fournisseur d'extraits fixture indisponible", "err", err)
+				continue
+			}
+			fixture, err := preview.ParseFixture(data)
+			if err != nil {
+				slog.Error("Fournisseur d'extraits fixture indisponible", "path", cfg.PreviewFixtures, "err", err)
+				continue
+			}
+			providers = append(providers, fixture)
+		default:
+			slog.Warn("Fournisseur d'extraits inconnu", "provider", name)
+		}
+	}
+	return providers
+}
+
+// newGeocoder choisit le géocodeur selon cfg.Geocoder ; nil désactive le géocodage
+func newGeocoder(cfg *core.Config) geo.Geocoder {
+	switch cfg.Geocoder {
+	case "none":
+		return nil
+	case "fixture":
+		data, err := readFixture(cfg.GeocoderFixtures)
+		if err != nil {
+			slog.Error("Géocodeur fixture indisponible", "err", err)
+			return nil
+		}
+		fixture, err := geo.ParseFixture(data)
+		if err != nil {
+			slog.Error("Géocodeur fixture indisponible", "path", cfg.GeocoderFixtures, "err", err)
+			return nil
+		}
+		return fixture
+	default:
+		return geo.RateLimit(geo.NewNominatim(cfg.NominatimURL, cfg.NominatimAgent), cfg.GeocoderInterval)
 	}
 }
 
@@ -47,153 +184,256 @@ func main() {
 	// Charger la configuration depuis les variables d'environnement
 	cfg := core.LoadConfig()
 
-	// Initialisation de la base de données PostgreSQL pour les favoris
-	if err := database.InitDB(); err != nil {
-		log.Printf("❌ ERREUR InitDB(): %v", err)
-		log.Println("Le serveur continuera sans fonctionnalités de favoris")
-	} else {
-		log.Println("✅ Base de données initialisée avec succès")
-		defer database.CloseDB()
-	}
-
-	// Route pour les fichiers statiques
-	staticDir := filepath.Join("web", "static")
-	log.Printf("static root: %s", staticDir)
-
-	http.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
-		// trim leading /static/
-		reqPath := r.URL.Path[len("/static/"):]
-		full := filepath.Join(staticDir, filepath.FromSlash(reqPath))
-
-		if fi, err := os.Stat(full); err == nil && !fi.IsDir() {
-			// Set correct content-type based on file extension
-			ext := filepath.Ext(full)
-			switch ext {
-			case ".css":
-				w.Header().Set("Content-Type", "text/css")
-			case ".js":
-				w.Header().Set("Content-Type", "application/javascript")
-			case ".png":
-				w.Header().Set("Content-Type", "image/png")
-			case ".jpg", ".jpeg":
-				w.Header().Set("Content-Type", "image/jpeg")
-			case ".svg":
-				w.Header().Set("Content-Type", "image/svg+xml")
-			case ".gif":
-				w.Header().Set("Content-Type", "image/gif")
-			case ".webp":
-				w.Header().Set("Content-Type", "image/webp")
-			}
-
-			// Add cache control for static assets
-			w.Header().Set("Cache-Control", "public, max-age=31536000")
+	// Logs structurés : JSON en production, texte en développement
+	logging.Setup(cfg.LogLevel, cfg.Environment)
 
-			http.ServeFile(w, r, full)
-			return
+	// Initialisation de la base de données PostgreSQL pour les favoris. Les
+	// stores sont choisis au démarrage : si la base est requise (READY_REQUIRED),
+	// le serveur s'arrête plutôt que de tourner sans persistance et rester non prêt
+	if health.Requires(cfg.ReadyRequired, "database") {
+		if err := database.InitDBRetry(dbStartupAttempts, 2*time.Second); err != nil {
+			slog.Error("Base de données requise indisponible, arrêt", "err", err)
+			os.Exit(1)
 		}
-		// fallback: let the default file server return 404
-		http.NotFound(w, r)
-	})
+	} else if err := database.InitDB(); err != nil {
+		slog.Error("Base de données indisponible, le serveur continue sans persistance", "err", err)
+	}
 
-	// Proxy audio pour contourner CORS sur les previews externes
-	http.HandleFunc("/api/audio-proxy", func(w http.ResponseWriter, r *http.Request) {
-		url := r.URL.Query().Get("url")
-		if url == "" {
-			http.Error(w, "missing url", http.StatusBadRequest)
-			return
-		}
-		// Certaines API refusent les requêtes sans User-Agent : forçons-en un.
-		req, err := http.NewRequest(http.MethodGet, url, nil)
-		if err != nil {
-			http.Error(w, "invalid url", http.StatusBadRequest)
-			return
-		}
-		req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; GroupieProxy/1.0)")
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			http.Error(w, "upstream error", http.StatusBadGateway)
-			return
-		}
-		defer resp.Body.Close()
+	// Pages et fichiers statiques embarqués (ASSETS_DIR : depuis le disque en développement)
+	var webFS fs.FS = webFiles
+	if cfg.AssetsDir != "" {
+		webFS = os.DirFS(cfg.AssetsDir)
+		slog.Info("Fichiers web servis depuis le disque", "dir", cfg.AssetsDir)
+	}
+	pages, err := assets.New(webFS, cfg.AssetsDir != "")
+	if err != nil {
+		slog.Error("Erreur chargement des fichiers web", "err", err)
+		os.Exit(1)
+	}
+	http.Handle("/static/", pages.Static())
 
-		// Copier content-type si présent
-		if ct := resp.Header.Get("Content-Type"); ct != "" {
-			w.Header().Set("Content-Type", ct)
-		} else {
-			w.Header().Set("Content-Type", "audio/mpeg")
+	// Proxy audio pour contourner CORS sur les previews externes (hôtes autorisés uniquement)
+	var audioCache *audio.Cache
+	if cfg.AudioCacheMaxSize > 0 {
+		if audioCache, err = audio.NewCache(cfg.AudioCacheDir, cfg.AudioCacheMaxSize); err != nil {
+			slog.Warn("Cache audio désactivé", "err", err)
 		}
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.WriteHeader(resp.StatusCode)
-		io.Copy(w, resp.Body)
-	})
+	}
+	audioProxy := audio.NewProxy(audio.ParseHostList(cfg.AudioProxyHosts), cfg.AudioProxyMaxSize, cfg.AudioProxyTimeout, audioCache)
+	http.Handle("/api/audio-proxy", audioProxy)
 
 	// Routes pour les templates
-	http.HandleFunc("/search.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, filepath.Join("web", "templates", "search.html"))
-	})
+	http.HandleFunc("/search.html", pages.ServePage("web/templates/search.html"))
+	http.HandleFunc("/geoloc.html", pages.ServePage("web/templates/geoloc.html"))
+	http.HandleFunc("/favorites.html", pages.ServePage("web/templates/favorites.html"))
+	http.HandleFunc("/login", pages.ServePage("web/templates/login.html"))
 
-	http.HandleFunc("/geoloc.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, filepath.Join("web", "templates", "geoloc.html"))
-	})
+	// Catalogue en mémoire des données Groupie Trackers, rafraîchi en arrière-plan
+	// (tâches de fond arrêtées par cancel à l'arrêt du serveur)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var background sync.WaitGroup
+	client := groupie.NewClient(cfg.GroupieTrackerAPI, cfg.GroupieTimeout)
+	cat := catalog.New(client, cfg.CatalogRefresh)
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		cat.Run(ctx)
+	}()
 
-	http.HandleFunc("/favorites.html", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, filepath.Join("web", "templates", "favorites.html"))
+	// Routes API avec proxy (servies depuis le catalogue)
+	relations := proxyCatalog(cat, func(s *catalog.Snapshot) interface{} {
+		return groupie.RelationIndex{Index: s.Relations}
 	})
+	http.HandleFunc("/api/artists-proxy", proxyCatalog(cat, func(s *catalog.Snapshot) interface{} {
+		return s.Artists
+	}))
+	http.HandleFunc("/api/locations-proxy", proxyCatalog(cat, func(s *catalog.Snapshot) interface{} {
+		return groupie.LocationIndex{Index: s.Locations}
+	}))
+	http.HandleFunc("/api/dates-proxy", proxyCatalog(cat, func(s *catalog.Snapshot) interface{} {
+		return groupie.DatesIndex{Index: s.Dates}
+	}))
+	// Alias avec et sans 's' pour éviter les erreurs de route
+	http.HandleFunc("/api/relation-proxy", relations)
+	http.HandleFunc("/api/relations-proxy", relations)
 
-	http.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, filepath.Join("web", "templates", "login.html"))
+	// État du catalogue (dernier rafraîchissement, santé de l'API distante)
+	http.HandleFunc("/api/catalog/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cat.Status())
 	})
 
-	// Routes API avec proxy
-	http.HandleFunc("/api/artists-proxy", proxyAPI(fmt.Sprintf("%s/artists", cfg.GroupieTrackerAPI)))
-	http.HandleFunc("/api/locations-proxy", proxyAPI(fmt.Sprintf("%s/locations", cfg.GroupieTrackerAPI)))
-	http.HandleFunc("/api/dates-proxy", proxyAPI(fmt.Sprintf("%s/dates", cfg.GroupieTrackerAPI)))
-	// Alias avec et sans 's' pour éviter les erreurs de route
-	http.HandleFunc("/api/relation-proxy", proxyAPI(fmt.Sprintf("%s/relation", cfg.GroupieTrackerAPI)))
-	http.HandleFunc("/api/relations-proxy", proxyAPI(fmt.Sprintf("%s/relation", cfg.GroupieTrackerAPI)))
+	// Géocodage côté serveur des lieux de concerts (cache PostgreSQL)
+	var geoStore geo.Store = geo.NewMemoryStore()
+	if database.DB != nil {
+		geoStore = geo.NewPostgresStore(database.DB)
+	}
+	geocoder := newGeocoder(cfg)
+	geoService := geo.NewService(geocoder, geoStore)
+	// Les coordonnées déjà connues sont servies même sans géocodeur (GEOCODER=none)
+	if err := geoService.Load(ctx); err != nil {
+		slog.Warn("Chargement des lieux géocodés échoué", "err", err)
+	}
+	if geocoder != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			geoService.Run(ctx, cat)
+		}()
+	}
+	http.HandleFunc("/api/locations/geo", handlers.GeoLocations(geoService, cat))
+	http.HandleFunc("GET /api/artists/{id}/tour.geojson", handlers.ArtistTour(geoService, cat))
 
-	// Routes API pour les favoris
-	http.HandleFunc("/api/favorites", func(w http.ResponseWriter, r *http.Request) {
-		// Support des options CORS
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	// Extraits musicaux résolus côté serveur (cache PostgreSQL)
+	var previewStore preview.Store = preview.NewMemoryStore()
+	if database.DB != nil {
+		previewStore = preview.NewPostgresStore(database.DB)
+	}
+	previews := preview.NewService(previewStore, newPreviewProviders(cfg)...)
+	http.HandleFunc("GET /api/artists/{id}/preview", handlers.ArtistPreview(previews, cat))
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	// Recherche côté serveur sur le catalogue
+	engine := search.NewEngine(cat)
+	http.HandleFunc("/api/search", handlers.Search(engine))
+	http.HandleFunc("/api/suggest", handlers.Suggest(engine))
+	http.HandleFunc("/api/artists", handlers.Artists(engine))
+
+	// Favoris des utilisateurs connectés (en mémoire sans base de données)
+	var favoriteStore favorites.Store = favorites.NewMemoryStore()
+	if database.DB != nil {
+		favoriteStore = favorites.NewPostgresStore(database.DB)
+	}
+	apiGetFavorites := handlers.GetFavorites(favoriteStore)
+	apiAddFavorite := handlers.AddFavorite(favoriteStore)
+	apiRemoveFavorite := handlers.RemoveFavorite(favoriteStore)
 
+	// Routes API pour les favoris
+	http.HandleFunc("/api/favorites", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			handlers.GetFavorites(w, r)
+			apiGetFavorites(w, r)
 		case http.MethodPost:
-			handlers.AddFavorite(w, r)
+			apiAddFavorite(w, r)
 		case http.MethodDelete:
-			handlers.RemoveFavorite(w, r)
+			apiRemoveFavorite(w, r)
 		default:
 			http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
 		}
 	})
 
-	http.HandleFunc("/api/favorites/check", handlers.CheckFavorite)
-	log.Println("✅ Routes API favoris enregistrées")
+	http.HandleFunc("/api/favorites/check", handlers.CheckFavorite(favoriteStore))
+
+	// Sondes de supervision : processus vivant, dépendances disponibles
+	http.HandleFunc("GET /healthz", health.Live)
+	http.HandleFunc("GET /readyz", newHealthChecker(cfg, client, cat).Ready())
+
+	// Métriques au format Prometheus, réservées au collecteur qui présente METRICS_TOKEN
+	registerMetrics(cat, favoriteStore)
+	if cfg.MetricsToken != "" {
+		http.Handle("GET /metrics", metrics.RequireToken(cfg.MetricsToken, metrics.Default.Handler()))
+	} else {
+		slog.Info("METRICS_TOKEN absent : /metrics désactivé")
+	}
+
+	// Comptes utilisateurs (inscription / connexion / sessions)
+	var users *auth.Users
+	var sessions *auth.Sessions
+	var tokens *auth.Tokens
+	if database.DB != nil {
+		users = auth.NewUsers(database.DB)
+		sessions = auth.NewSessions(database.DB, users, cfg.SessionSecret)
+		tokens = auth.NewTokens(database.DB, users, cfg.JWTSecret)
+	}
+	http.HandleFunc("/api/register", handlers.Register(users))
+	http.HandleFunc("/api/login", handlers.Login(users, sessions, favoriteStore))
+	http.HandleFunc("/api/logout", handlers.Logout(sessions))
+	http.HandleFunc("/api/me", handlers.Me)
+
+	// Jetons JWT pour les clients API (scripts, mobile)
+	http.HandleFunc("/api/token", handlers.Token(users, tokens))
+	http.HandleFunc("/api/token/refresh", handlers.RefreshToken(tokens))
+	http.HandleFunc("/api/token/revoke", handlers.RevokeToken(tokens))
 
 	// Routes favoris (package main)
-	http.HandleFunc("/favorites", favoritesPage)
-	http.HandleFunc("/favorites/add", addFavorite)
-	http.HandleFunc("/favorites/remove", removeFavorite)
+	http.HandleFunc("/favorites", favoritesPage(favoriteStore, pages))
+	http.HandleFunc("/favorites/add", addFavorite(favoriteStore))
+	http.HandleFunc("/favorites/remove", removeFavorite(favoriteStore))
 
 	// Route racine pour index.html
+	index := pages.ServePage("index.html")
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.NotFound(w, r)
 			return
 		}
-		http.ServeFile(w, r, "index.html")
+		index(w, r)
 	})
 
-	port := cfg.Port
-	log.Printf("Starting server on :%s — open http://localhost:%s/", port, port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	// Authentification : Bearer JWT prioritaire sur le cookie de session
+	var handler http.Handler = http.DefaultServeMux
+	if tokens != nil {
+		handler = tokens.Middleware(handler)
+	}
+	if sessions != nil {
+		handler = sessions.Middleware(handler)
+	}
+	// CORS en dernier : les preflight sont traités avant l'authentification
+	handler = cors.New(cfg.AllowedOrigins).Middleware(handler)
+	// Compteurs et latences par route (motif du ServeMux)
+	handler = metrics.Middleware(http.DefaultServeMux, handler)
+	// X-Request-ID et log d'accès autour de toutes les routes
+	handler = logging.Middleware(handler)
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	slog.Info("Serveur démarré", "addr", srv.Addr, "url", "http://localhost:"+cfg.Port+"/")
+	serveErr := serve(srv, cfg.ShutdownTimeout)
+	if serveErr != nil {
+		slog.Error("Arrêt du serveur", "err", serveErr)
+	}
+
+	// Requêtes terminées : arrêt des tâches de fond, puis de la base
+	cancel()
+	background.Wait()
+	database.CloseDB()
+	slog.Info("Serveur arrêté")
+	if serveErr != nil {
+		os.Exit(1)
+	}
+}
+
+// serve écoute avec srv jusqu'à SIGTERM (Scalingo, Docker) ou SIGINT (Ctrl+C).
+// Au signal, les nouvelles connexions sont refusées et les requêtes en cours,
+// extraits audio compris, ont jusqu'à timeout pour se terminer ; les
+// connexions restantes sont ensuite coupées. Retourne l'erreur d'écoute.
+func serve(srv *http.Server, timeout time.Duration) error {
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-signals.Done():
+	}
+	stop() // un second signal interrompt le processus sans attendre
+	slog.Info("Arrêt demandé, fin des requêtes en cours", "timeout", timeout.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("Requêtes encore en cours à l'échéance, connexions fermées", "err", err)
+		srv.Close()
+	}
+	return nil
 }
@@ -4,7 +4,7 @@
 // Ce script:
 // - Charge artistes et relations (dates↔lieux) via proxy API
 // - Agrège les données par lieu
-// - Géocode chaque lieu avec Nominatim (OpenStreetMap) avec cache localStorage
+// - Récupère les coordonnées géocodées par le serveur (/api/locations/geo)
 // - Place des marqueurs Leaflet et ajuste la vue aux bounds
 // - Construit des popups HTML avec artistes et dates
 // ============================================================================
@@ -30,10 +30,8 @@
 	const ARTISTS_URL = '/api/artists-proxy';
 	const RELATION_URL = '/api/relation-proxy';
 
-	// Clé de cache pour localStorage (minuscule pour uniformiser)
-	const cacheKey = (loc) => `geocode:${loc.toLowerCase()}`;
-	// Petite pause pour respecter la politesse avec Nominatim
-	const sleep = (ms) => new Promise((res) => setTimeout(res, ms));
+	// Coordonnées géocodées par le serveur (cache PostgreSQL, Nominatim côté Go)
+	const GEO_URL = '/api/locations/geo';
 
 	// Helper générique pour charger du JSON avec vérification HTTP
 	async function fetchJson(url) {
@@ -76,29 +74,14 @@
 		return { artists, relationByLocation: byLocation };
 	}
 
-	// Géocoder un lieu avec Nominatim, avec cache localStorage
+	// Charger une seule fois les coordonnées calculées par le serveur
+	let geoPoints = null;
 	async function geocodeLocation(loc) {
-		const key = cacheKey(loc);
-		const cached = localStorage.getItem(key);
-		if (cached) {
-			try {
-				return JSON.parse(cached);
-			} catch {}
+		if (!geoPoints) {
+			const data = await fetchJson(GEO_URL);
+			geoPoints = new Map((data.locations || []).map((l) => [l.location, { lat: l.lat, lon: l.lon }]));
 		}
-		// Use Nominatim to geocode the textual location
-		const url = `https://nominatim.openstreetmap.org/search?format=json&q=${encodeURIComponent(loc)}`;
-		const res = await fetch(url, {
-			headers: { 'Accept-Language': 'fr' },
-		});
-		if (!res.ok) throw new Error('Geocode failed: ' + res.status);
-		const arr = await res.json();
-		if (!Array.isArray(arr) || arr.length === 0) return null;
-		const best = arr[0];
-		const point = { lat: parseFloat(best.lat), lon: parseFloat(best.lon) };
-		localStorage.setItem(key, JSON.stringify(point));
-		// be polite with Nominatim; small delay between requests
-		await sleep(250);
-		return point;
+		return geoPoints.get(loc) || null;
 	}
 
 	// Construire le HTML du popup (liste artistes + dates uniques)
@@ -2,9 +2,9 @@
 // PAGE RECHERCHE: SUGGESTIONS, FILTRES RAPIDES, MODAL DÉTAIL ARTISTE
 // ============================================================================
 // Ce script gère:
-// - Le chargement des artistes via proxy + fallback
+// - Le chargement des artistes depuis le catalogue du serveur
 // - La recherche par nom avec suggestions instantanées
-// - Les filtres rapides (chips) appliqués dynamiquement
+// - Les filtres rapides (chips), résolus côté serveur par /api/artists
 // - L'affichage d'un modal détail pour un artiste
 // - L'accessibilité clavier (Enter/Espace) et Escape pour fermer
 // ============================================================================
@@ -17,9 +17,10 @@ document.addEventListener('DOMContentLoaded', () => {
 	const quickFilters = document.getElementById('quickFilters');
 	const clearBtn = document.getElementById('clearSearch');
 
-	// Cache local des artistes (chargé une seule fois)
-	let allArtists = [];
-	// Filtre actif (id du chip), null si aucun
+	// Minuteur de l'autocomplétion (debounce) et numéro de la dernière requête
+	let suggestTimer = null;
+	let suggestSeq = 0;
+	// Filtre actif (paramètres /api/artists du chip, ex: "country=usa"), null si aucun
 	let activeFilter = null;
 	// Références au modal (conteneur et backdrop overlay)
 	let modalEl = null;
@@ -141,67 +142,40 @@ document.addEventListener('DOMContentLoaded', () => {
 		if (modalBackdrop) modalBackdrop.classList.add('open');
 	}
 
-	// Charger et mettre en cache les données artistes (proxy + fallback API)
-	async function ensureData() {
-		if (allArtists.length) return allArtists;
-
-		// Helper pour fetch JSON avec vérification d'état HTTP
-		async function fetchArtists(url) {
-			const resp = await fetch(url, { headers: { 'Accept': 'application/json' } });
-			if (!resp.ok) throw new Error('Réponse réseau incorrecte: ' + resp.status);
-			return resp.json();
-		}
-
-		let data;
-		try {
-			data = await fetchArtists('/api/artists-proxy');
-		} catch (err) {
-			// Fallback direct vers l'API publique si le proxy n'est pas dispo (Netlify/statique)
-			try {
-				data = await fetchArtists('https://groupietrackers.herokuapp.com/api/artists');
-			} catch (fallbackErr) {
-				throw fallbackErr;
-			}
-		}
-
-		allArtists = Array.isArray(data) ? data : (data.artists || []);
-		return allArtists;
-	}
-
-	// Appliquer un filtre rapide (chip) sur un artiste donné
-	function filterByBadge(artist, filterId) {
-		if (!filterId) return true;
-		const name = (artist.name || '').toLowerCase();
-		const creation = Number(artist.creationDate || artist.creation_date || 0);
-		const albumYear = parseInt((artist.firstAlbum || '').slice(-4), 10);
-		const location = ((artist.country || artist.location || artist.place || '') + '').toLowerCase();
-
-		switch (filterId) {
-			case 'rock':
-				return /rock|metal|punk|roll/.test(name);
-			case 'seventies':
-				return (creation >= 1970 && creation < 1980) || (albumYear >= 1970 && albumYear < 1980);
-			case 'usa':
-				if (!location) return true; // pas d'info, on n'exclut pas
-				return /(usa|united states|new york|los angeles|california)/.test(location);
-			case 'month':
-				// pas de dates précises dans l'API de base : on conserve mais on limite
-				return true;
-			default:
-				return true;
+	// Requête JSON vers l'API du serveur ; un 503 (catalogue en cours de
+	// chargement) donne une erreur portant le délai Retry-After
+	async function fetchJSON(url) {
+		const resp = await fetch(url, { headers: { 'Accept': 'application/json' } });
+		if (!resp.ok) {
+			const err = new Error('Réponse réseau incorrecte: ' + resp.status);
+			err.status = resp.status;
+			err.retryAfter = parseInt(resp.headers.get('Retry-After'), 10) || 0;
+			throw err;
 		}
+		return resp.json();
 	}
 
-	// Afficher les résultats sous forme de cartes cliquables
-	function renderResults(list) {
+	// Libellés des champs qui ont fait correspondre un artiste (/api/search)
+	const MATCH_LABELS = {
+		artist: 'Nom',
+		member: 'Membre',
+		location: 'Lieu de concert',
+		creation_date: 'Création',
+		first_album: 'Premier album'
+	};
+
+	// Afficher les résultats (hits de /api/search ou /api/artists) sous forme
+	// de cartes cliquables
+	function renderResults(hits) {
 		if (!results) return;
 		results.innerHTML = '';
-		if (!list.length) {
+		if (!hits.length) {
 			results.innerHTML = '<p>Aucun artiste trouvé.</p>';
 			return;
 		}
 
-		list.forEach((artist, idx) => {
+		hits.forEach((hit, idx) => {
+			const artist = hit.artist || {};
 			const card = document.createElement('article');
 			card.className = 'artist-card';
 			card.tabIndex = 0;
@@ -234,6 +208,15 @@ document.addEventListener('DOMContentLoaded', () => {
 				body.appendChild(p);
 			}
 
+			// Champ ayant fait correspondre l'artiste, s'il ne s'agit pas du nom
+			const match = (hit.matches || []).find(m => m.field !== 'artist');
+			if (match) {
+				const p = document.createElement('p');
+				p.className = 'artist-meta';
+				p.textContent = `${MATCH_LABELS[match.field] || match.field} : ${match.value}`;
+				body.appendChild(p);
+			}
+
 			const genreVal = artist.genre;
 			if (genreVal) {
 				const p = document.createElement('p');
@@ -286,61 +269,104 @@ document.addEventListener('DOMContentLoaded', () => {
 		});
 	}
 
-	// Effectuer la recherche par nom, appliquer filtre actif, et afficher
-	async function performSearch(q) {
+	// Nombre de résultats par page
+	const PAGE_SIZE = 24;
+
+	// Effectuer la recherche côté serveur : /api/search avec un texte (nom,
+	// membres, lieux, dates), /api/artists sans texte, avec le filtre rapide
+	// actif, puis afficher la page demandée
+	async function performSearch(q, page = 1) {
 		if (!results) return;
 		results.innerHTML = '<p>Recherche en cours…</p>';
+		const text = String(q || '').trim();
+		const params = new URLSearchParams(activeFilter || '');
+		if (text) params.set('q', text);
+		params.set('page', String(page));
+		params.set('limit', String(PAGE_SIZE));
 		try {
-			const data = await ensureData();
-			if (!Array.isArray(data) || data.length === 0) {
-				results.innerHTML = '<p>Aucun artiste disponible depuis l\'API.</p>';
-				return;
-			}
-
-			const qLower = String(q || '').toLowerCase();
-			let filtered = qLower
-				? data.filter(a => (a.name || '').toLowerCase().includes(qLower))
-				: data.slice(0, 24);
+			const data = await fetchJSON((text ? '/api/search?' : '/api/artists?') + params.toString());
+			renderResults(data.results || []);
+			renderPager(text, data.page || page, data.total || 0, data.limit || PAGE_SIZE);
+		} catch (err) {
+			results.innerHTML = errorMessage(err);
+		}
+	}
 
-			// Appliquer le filtre rapide actif
-			filtered = filtered.filter(a => filterByBadge(a, activeFilter));
+	// Afficher la pagination sous les résultats
+	function renderPager(q, page, total, limit) {
+		const pages = Math.ceil(total / limit);
+		if (!results || pages <= 1) return;
+		const pager = document.createElement('nav');
+		pager.className = 'search-pager';
+		pager.setAttribute('aria-label', 'Pages de résultats');
 
-			if (filtered.length === 0) {
-				results.innerHTML = '<p>Aucun artiste trouvé.</p>';
-				return;
-			}
+		const button = (label, target) => {
+			const btn = document.createElement('button');
+			btn.type = 'button';
+			btn.className = 'btn-ghost';
+			btn.textContent = label;
+			btn.disabled = target < 1 || target > pages;
+			btn.addEventListener('click', () => performSearch(q, target));
+			return btn;
+		};
+		const info = document.createElement('span');
+		info.textContent = `Page ${page} / ${pages} — ${total} artistes`;
+
+		pager.appendChild(button('← Précédente', page - 1));
+		pager.appendChild(info);
+		pager.appendChild(button('Suivante →', page + 1));
+		results.appendChild(pager);
+	}
 
-			renderResults(filtered);
-		} catch (err) {
-			results.innerHTML = `<p>Erreur lors de la recherche: ${escapeHtml(err.message)}</p>`;
-		}
+	// Libellés des types de suggestions de /api/suggest
+	const SUGGEST_LABELS = {
+		'artist/band': 'Artiste',
+		member: 'Membre',
+		location: 'Lieu'
+	};
+
+	// Cacher et vider le panneau de suggestions
+	function hideSuggestions() {
+		if (!suggestionsEl) return;
+		suggestionsEl.classList.remove('show');
+		suggestionsEl.innerHTML = '';
 	}
 
-	// Mettre à jour les suggestions instantanées sous l'input
-	function updateSuggestions() {
+	// Mettre à jour les suggestions sous l'input depuis /api/suggest
+	// (artistes, membres et lieux, dédoublonnés par le serveur)
+	async function updateSuggestions() {
 		if (!suggestionsEl || !input) return;
-		const q = input.value.trim().toLowerCase();
-		if (q.length < 2 || !allArtists.length) {
-			suggestionsEl.classList.remove('show');
-			suggestionsEl.innerHTML = '';
+		const q = input.value.trim();
+		if (q.length < 2) {
+			hideSuggestions();
 			return;
 		}
-		const matches = allArtists
-			.filter(a => (a.name || '').toLowerCase().startsWith(q))
-			.slice(0, 5);
-		if (!matches.length) {
-			suggestionsEl.classList.remove('show');
-			suggestionsEl.innerHTML = '';
+		// Ignorer les réponses arrivées après une saisie plus récente
+		const seq = ++suggestSeq;
+		let suggestions;
+		try {
+			suggestions = await fetchJSON('/api/suggest?' + new URLSearchParams({ q, limit: '8' }).toString());
+		} catch (err) {
+			suggestions = [];
+		}
+		if (seq !== suggestSeq) return;
+		if (!Array.isArray(suggestions) || !suggestions.length) {
+			hideSuggestions();
 			return;
 		}
 		suggestionsEl.innerHTML = '';
-		matches.forEach(m => {
+		suggestions.forEach(sug => {
 			const btn = document.createElement('button');
 			btn.type = 'button';
-			btn.textContent = m.name || '';
+			btn.setAttribute('role', 'option');
+			btn.textContent = sug.text || '';
+			const type = document.createElement('span');
+			type.className = 'suggestion-type';
+			type.textContent = SUGGEST_LABELS[sug.type] || sug.type;
+			btn.appendChild(type);
 			btn.addEventListener('click', () => {
-				input.value = m.name || '';
-				suggestionsEl.classList.remove('show');
+				input.value = sug.text || '';
+				hideSuggestions();
 				performSearch(input.value.trim());
 			});
 			suggestionsEl.appendChild(btn);
@@ -348,6 +374,12 @@ document.addEventListener('DOMContentLoaded', () => {
 		suggestionsEl.classList.add('show');
 	}
 
+	// Lancer l'autocomplétion 200 ms après la dernière frappe
+	function scheduleSuggestions() {
+		clearTimeout(suggestTimer);
+		suggestTimer = setTimeout(updateSuggestions, 200);
+	}
+
 	// Basculer l'état du filtre rapide et rafraîchir les résultats
 	function setActiveFilter(id) {
 		activeFilter = id === activeFilter ? null : id;
@@ -370,12 +402,8 @@ document.addEventListener('DOMContentLoaded', () => {
 
 	// Suggestions: écouter input/focus et clic ailleurs pour cacher
 	if (input) {
-		input.addEventListener('input', () => {
-			ensureData().then(updateSuggestions).catch(() => {});
-		});
-		input.addEventListener('focus', () => {
-			ensureData().then(updateSuggestions).catch(() => {});
-		});
+		input.addEventListener('input', scheduleSuggestions);
+		input.addEventListener('focus', scheduleSuggestions);
 		document.addEventListener('click', (e) => {
 			if (!suggestionsEl) return;
 			if (!suggestionsEl.contains(e.target) && e.target !== input) {
@@ -410,11 +438,16 @@ document.addEventListener('DOMContentLoaded', () => {
 		const q = params.get('q') || params.get('artist');
 		if (q) {
 			if (input) input.value = q;
-			ensureData().then(() => performSearch(q));
-		} else {
-			// Précharger les données pour activer les suggestions instantanées
-			ensureData().catch(() => {});
+			performSearch(q);
+		}
+	}
+
+	// Message d'erreur d'une recherche (catalogue pas encore chargé ou erreur)
+	function errorMessage(err) {
+		if (err.status === 503) {
+			return `<p>Catalogue en cours de chargement, réessayez dans ${err.retryAfter || 30} s.</p>`;
 		}
+		return `<p>Erreur lors de la recherche: ${escapeHtml(err.message)}</p>`;
 	}
 
 	// Échapper les caractères HTML pour éviter l'injection dans messages d'erreur
@@ -128,8 +128,8 @@ document.addEventListener('DOMContentLoaded', function () {
 // ============================================================================
 // Cette section gère l'affichage dynamique des artistes sous forme de vinyles
 // cliquables qui tournent au survol et jouent de la musique.
-// Les données proviennent de l'API Groupie Trackers via un proxy local pour
-// résoudre les problèmes CORS, avec fallback vers l'API distante.
+// Les données proviennent du catalogue en mémoire du serveur Go (routes
+// /api/*-proxy), jamais directement de l'API Groupie Trackers.
 // ============================================================================
 
 // Attendre le chargement complet du DOM avant d'initialiser les vinyles
@@ -139,50 +139,30 @@ document.addEventListener('DOMContentLoaded', function () {
 	console.log('🎵 ui.js: DOMContentLoaded event fired');
 	
 	// ========================================================================
-	// CONSTANTES D'API - SYSTÈME DE PROXY LOCAL + FALLBACK DISTANT
+	// CONSTANTES D'API - CATALOGUE DU SERVEUR
 	// ========================================================================
-	// Les URLs locales passent par notre serveur Go (main.go) qui agit comme
-	// proxy CORS. Si le proxy échoue, on bascule automatiquement vers l'API
-	// Groupie Trackers directe (avec risque de CORS selon le navigateur).
+	// Les URLs locales sont servies par notre serveur Go (main.go) depuis son
+	// catalogue en mémoire. Tant que le catalogue n'est pas chargé, elles
+	// répondent 503 avec un en-tête Retry-After : la page réessaie alors.
 	// ========================================================================
 	
 	// URL du proxy local pour récupérer la liste des artistes
 	// Route définie dans main.go : http.HandleFunc("/api/artists-proxy", ...)
 	const LOCAL_API = '/api/artists-proxy';
 	
-	// URL directe de l'API Groupie Trackers pour les artistes (fallback)
-	// Utilisée si le proxy local est indisponible ou retourne une erreur
-	const REMOTE_API = 'https://groupietrackers.herokuapp.com/api/artists';
-	
 	// URL du proxy local pour récupérer les lieux de concerts des artistes
 	// Format: {"index": [{"id": 1, "locations": ["usa-new_york", ...]}, ...]}
 	const LOCAL_LOCATIONS_API = '/api/locations-proxy';
 	
-	// URL directe de l'API Groupie Trackers pour les locations (fallback)
-	const REMOTE_LOCATIONS_API = 'https://groupietrackers.herokuapp.com/api/locations';
-	
 	// URL du proxy local pour récupérer les dates de concerts des artistes
 	// Format: {"index": [{"id": 1, "dates": ["*23-08-2019", ...]}, ...]}
 	const LOCAL_DATES_API = '/api/dates-proxy';
 	
-	// URL directe de l'API Groupie Trackers pour les dates (fallback)
-	const REMOTE_DATES_API = 'https://groupietrackers.herokuapp.com/api/dates';
-	
 	// URL du proxy local pour récupérer les relations dates↔lieux
 	// Format: {"index": [{"id": 1, "datesLocations": {"usa-new_york": ["*23-08-2019"], ...}}, ...]}
 	// Correspond à la route Go /api/relation-proxy (sans s)
 	const LOCAL_RELATIONS_API = '/api/relation-proxy';
-	
-	// URL directe de l'API Groupie Trackers pour les relations (fallback)
-	// Note: l'API utilise '/relation' (singulier) au lieu de '/relations'
-	const REMOTE_RELATIONS_API = 'https://groupietrackers.herokuapp.com/api/relation';
-
-	// Jeu de données minimal de secours si les APIs échouent
-	const SAMPLE_ARTISTS = [
-		{ id: 0, name: 'Artist Demo 1', image: '/static/images/vinyle.png', members: [], creationDate: 2000 },
-		{ id: 1, name: 'Artist Demo 2', image: '/static/images/vinyle.png', members: [], creationDate: 2005 }
-	];
-	
+
 	// MP3 de secours (3 secondes) utilisé si iTunes et Deezer échouent
 	// Permet de toujours avoir un audio jouable même sans aperçu musical trouvé
 	const FALLBACK_PREVIEW = 'https://samplelib.com/lib/preview/mp3/sample-3s.mp3';
@@ -258,8 +238,14 @@ document.addEventListener('DOMContentLoaded', function () {
 		const res = await fetch(url, {cache: 'no-store'});
 		
 		// Vérifier le code de statut HTTP (res.ok = true si 200-299)
-		// Lance une exception si erreur 4xx ou 5xx
-		if (!res.ok) throw new Error('API response ' + res.status);
+		// Lance une exception si erreur 4xx ou 5xx ; pour un 503 (catalogue
+		// en cours de chargement), l'exception porte le délai Retry-After
+		if (!res.ok) {
+			const err = new Error('API response ' + res.status);
+			err.status = res.status;
+			err.retryAfter = parseInt(res.headers.get('Retry-After'), 10) || 0;
+			throw err;
+		}
 		
 		// Parser le body JSON et le retourner comme objet JavaScript
 		// Lance automatiquement une exception si le JSON est invalide
@@ -267,75 +253,41 @@ document.addEventListener('DOMContentLoaded', function () {
 	}
 
 	// ========================================================================
-	// FONCTIONS DE CHARGEMENT DES DONNÉES AVEC PROXY + FALLBACK
+	// FONCTIONS DE CHARGEMENT DES DONNÉES DU CATALOGUE
 	// ========================================================================
-	// Chaque fonction essaie d'abord le proxy local (plus rapide, pas de CORS)
-	// puis bascule vers l'API distante en cas d'échec.
-	// Cela garantit la résilience de l'application même si le backend est down.
+	// Chaque fonction charge une vue du catalogue du serveur. Un échec n'est
+	// pas bloquant : le modal affichera simplement moins d'informations.
 	// ========================================================================
 
 	// Charger les lieux de concerts (locations) de tous les artistes
 	async function loadLocations() {
 		try {
-			// Tentative 1 : charger depuis le proxy local Go (route /api/locations-proxy)
-			// Avantage : pas de problème CORS, plus rapide car même domaine
 			locationsData = await tryFetch(LOCAL_LOCATIONS_API);
 		} catch (err) {
-			// Si le proxy local échoue (serveur down, erreur 500, etc.)
-			// tenter de charger directement depuis l'API Groupie Trackers
-			try {
-				locationsData = await tryFetch(REMOTE_LOCATIONS_API);
-			} catch (err2) {
-				// Si les deux sources échouent, logger l'erreur mais ne pas crasher
-				// L'application continuera sans données de lieux (modal affichera "Aucun lieu")
-				console.warn('Failed to load locations from both proxy and remote API', err, err2);
-			}
+			// L'application continuera sans données de lieux (modal affichera "Aucun lieu")
+			console.warn('Failed to load locations', err);
 		}
 	}
 
 	// Charger les dates de concerts de tous les artistes
 	async function loadDates() {
 		try {
-			// Tentative 1 : charger depuis le proxy local Go (route /api/dates-proxy)
 			datesData = await tryFetch(LOCAL_DATES_API);
 		} catch (err) {
-			// Fallback vers l'API distante si le proxy local échoue
-			try {
-				datesData = await tryFetch(REMOTE_DATES_API);
-			} catch (err2) {
-				// Si les deux sources échouent, continuer sans dates
-				// Le modal affichera "Aucune date connue"
-				console.warn('Failed to load dates from both proxy and remote API', err, err2);
-			}
+			// Le modal affichera "Aucune date connue"
+			console.warn('Failed to load dates', err);
 		}
 	}
 
 	// Charger les relations (mapping dates↔lieux) de tous les artistes
 	async function loadRelations() {
 		try {
-			// Tentative 1 : charger depuis le proxy local Go (route /api/relations-proxy)
 			relationsData = await tryFetch(LOCAL_RELATIONS_API);
-			
-			// Message de succès pour le debug
-			console.log('✅ Relations loaded from local proxy');
+			console.log('✅ Relations loaded');
 		} catch (err) {
-			// Message d'avertissement si le proxy local échoue
-			console.warn('⚠️ Local relations API failed, trying remote...', err);
-			
-			try {
-				// Fallback vers l'API distante Groupie Trackers
-				relationsData = await tryFetch(REMOTE_RELATIONS_API);
-				
-				// Message de succès pour le fallback
-				console.log('✅ Relations loaded from remote API');
-			} catch (err2) {
-				// Si les deux sources échouent, logger l'erreur complète
-				console.error('❌ Failed to load relations from both APIs', err, err2);
-				
-				// Créer un objet par défaut vide pour éviter les erreurs null
-				// L'application continuera à fonctionner sans données de relations
-				relationsData = { index: [] };
-			}
+			console.error('❌ Failed to load relations', err);
+			// Objet par défaut vide pour éviter les erreurs null
+			relationsData = { index: [] };
 		}
 	}
 
@@ -392,6 +344,15 @@ document.addEventListener('DOMContentLoaded', function () {
 		return artistRel ? artistRel.datesLocations : null;
 	}
 
+	// Afficher un message d'état à la place des vinyles (chargement, erreur)
+	function showGridMessage(text) {
+		vinylGrid.innerHTML = '';
+		const p = document.createElement('p');
+		p.className = 'vinyl-status';
+		p.textContent = text;
+		vinylGrid.appendChild(p);
+	}
+
 	// ========================================================================
 	// FONCTION PRINCIPALE : CHARGEMENT ET AFFICHAGE DES ARTISTES
 	// ========================================================================
@@ -424,26 +385,21 @@ document.addEventListener('DOMContentLoaded', function () {
 		let data;
 		
 		try {
-			// Tentative de chargement depuis le proxy local (plus rapide, pas de CORS)
-			console.log('📡 Fetching artists from local proxy...');
+			console.log('📡 Fetching artists from catalog...');
 			data = await tryFetch(LOCAL_API);
-			console.log('✅ Artists loaded from local proxy');
+			console.log('✅ Artists loaded');
 		} catch (err) {
-			// Si le proxy local échoue, avertir et tenter le fallback
-			console.warn('⚠️ Local proxy failed, trying remote API...', err);
-			
-			try {
-				// Fallback : charger directement depuis l'API Groupie Trackers
-				// Peut échouer à cause de CORS selon la configuration du navigateur
-				data = await tryFetch(REMOTE_API);
-				console.log('✅ Artists loaded from remote API');
-			} catch (err2) {
-				// Si les deux sources échouent, abandonner complètement
-				// L'application ne peut pas fonctionner sans données d'artistes
-				console.error('❌ Failed to load artists from both APIs', err, err2);
-				// Utiliser le fallback local minimal pour afficher au moins 2 vinyles
-				data = SAMPLE_ARTISTS;
+			console.error('❌ Failed to load artists', err);
+			if (err.status === 503) {
+				// Catalogue en cours de chargement côté serveur : réessayer
+				// après le délai indiqué par Retry-After
+				const delay = err.retryAfter || 30;
+				showGridMessage(`Catalogue en cours de chargement, nouvel essai dans ${delay} s…`);
+				setTimeout(loadArtists, delay * 1000);
+			} else {
+				showGridMessage('Impossible de charger les artistes. Réessayez plus tard.');
 			}
+			return;
 		}
 
 		// Normaliser le format des données (gérer différentes structures de réponse)
@@ -555,148 +511,53 @@ document.addEventListener('DOMContentLoaded', function () {
 			// ================================================================
 			// FONCTION DE RECHERCHE DE PREVIEW MUSICALE
 			// ================================================================
-			// Cherche une preview audio de 30s pour l'artiste donné en essayant
-			// d'abord iTunes (meilleure qualité) puis Deezer en fallback.
-			// Retourne l'URL HTTPS du MP3 ou null si aucune preview trouvée.
+			// Demande au serveur une preview audio de 30s pour l'artiste.
+			// Le serveur interroge iTunes puis Deezer, choisit le morceau
+			// correspondant le mieux au nom de l'artiste et met le résultat
+			// en cache. L'URL retournée passe déjà par /api/audio-proxy.
+			// Retourne l'URL de la preview ou null si aucune n'a été trouvée.
 			// ================================================================
 			
 			// Fonction asynchrone qui retourne Promise<string | null>
-			async function fetchMusicPreview(artistName) {
+			async function fetchMusicPreview(artist) {
 				// Si une recherche est déjà en cours, ne pas en lancer une autre
-				// Évite les doublons et la surcharge de l'API
 				if (audioLoading) return null;
 				
+				// Sans identifiant (artistes de démonstration), pas de preview
+				if (artist.id === undefined || artist.id === null) return null;
+				
 				// Marquer qu'une recherche est en cours
 				audioLoading = true;
 				
-				// Encoder le nom de l'artiste pour l'URL (remplace espaces, accents, etc.)
-				// Exemple : "Foo Fighters" → "Foo%20Fighters"
-				const encodedName = encodeURIComponent(artistName);
-				
 				// Message de debug pour tracer la recherche
-				console.log('🎵 Searching music for:', artistName);
+				console.log('🎵 Searching music for:', artist.name);
 				
-				// ============================================================
-				// TENTATIVE 1 : API ITUNES (PRIORITAIRE)
-				// ============================================================
-				// iTunes offre des previews de 30s de haute qualité audio.
-				// Format de l'API : https://itunes.apple.com/search?term=<artist>&entity=song&limit=1&media=music
-				// ============================================================
 				try {
-					// Construire l'URL de recherche iTunes
-					// entity=song : chercher uniquement des chansons (pas albums/artistes)
-					// limit=1 : récupérer seulement le premier résultat (économise bande passante)
-					// media=music : filtrer uniquement le contenu musical
-					const itunesUrl = `https://itunes.apple.com/search?term=${encodedName}&entity=song&limit=1&media=music`;
-					
-					// Debug : afficher l'URL complète pour vérification
-					console.log('📡 Fetching from iTunes:', itunesUrl);
-					
-					// Effectuer la requête HTTP vers iTunes
-					const itunesRes = await fetch(itunesUrl);
-					
-					// Parser la réponse JSON
-					const itunesData = await itunesRes.json();
-					
-					// Debug : afficher la structure de la réponse
-					console.log('📦 iTunes response:', itunesData);
+					// GET /api/artists/{id}/preview
+					// Réponse : {title, album, provider, preview_url: "/api/audio-proxy?url=..."}
+					const res = await fetch(`/api/artists/${encodeURIComponent(artist.id)}/preview`);
 					
-					// Vérifier si des résultats ont été trouvés
-					// Structure attendue : {resultCount: 1, results: [{previewUrl: "..."}]}
-					if (itunesData.results && itunesData.results.length > 0) {
-						// Extraire l'URL de preview du premier résultat
-						let preview = itunesData.results[0].previewUrl;
+					if (res.ok) {
+						const data = await res.json();
 						
-						// Vérifier que l'URL existe
-						if (preview) {
-							// Forcer HTTPS pour éviter les erreurs mixed content
-							// Les navigateurs bloquent les requêtes HTTP depuis des pages HTTPS
-							if (preview.startsWith('http://')) {
-								preview = preview.replace('http://', 'https://');
-							}
-							
-							// Succès : preview trouvée
-							console.log('✅ iTunes preview found:', preview);
-							
-							// Libérer le verrou de chargement
-							audioLoading = false;
-							
-							// Retourner l'URL HTTPS de la preview
-							return `/api/audio-proxy?url=${encodeURIComponent(preview)}`;
-						}
-					}
-					
-					// Aucune preview trouvée dans les résultats iTunes
-					console.log('⚠️ No iTunes results for:', artistName);
-				} catch (err) {
-					// Erreur lors de la requête iTunes (réseau, timeout, CORS, etc.)
-					console.error('❌ iTunes API error:', err);
-				}
-				
-				// ============================================================
-				// TENTATIVE 2 : API DEEZER (FALLBACK)
-				// ============================================================
-				// Si iTunes échoue, essayer Deezer qui offre aussi des previews 30s.
-				// Format de l'API : https://api.deezer.com/search?q=<artist>&limit=1
-				// ============================================================
-				try {
-					// Construire l'URL de recherche Deezer
-					// q= : paramètre de requête textuelle
-					// limit=1 : récupérer seulement le premier résultat
-					const deezerUrl = `https://api.deezer.com/search?q=${encodedName}&limit=1`;
-					
-					// Debug : afficher l'URL Deezer
-					console.log('📡 Fetching from Deezer:', deezerUrl);
-					
-					// Effectuer la requête HTTP vers Deezer
-					const deezerRes = await fetch(deezerUrl);
-					
-					// Parser la réponse JSON
-					const deezerData = await deezerRes.json();
-					
-					// Debug : afficher la structure de la réponse
-					console.log('📦 Deezer response:', deezerData);
-					
-					// Vérifier si des résultats ont été trouvés
-					// Structure attendue : {data: [{preview: "..."}]}
-					if (deezerData.data && deezerData.data.length > 0) {
-						// Extraire l'URL de preview du premier résultat
-						let preview = deezerData.data[0].preview;
+						// Succès : preview trouvée par le serveur
+						console.log(`✅ ${data.provider} preview found:`, data.title);
 						
-						// Vérifier que l'URL existe
-						if (preview) {
-							// Forcer HTTPS pour la sécurité
-							if (preview.startsWith('http://')) {
-								preview = preview.replace('http://', 'https://');
-							}
-							
-							// Succès : preview Deezer trouvée
-							console.log('✅ Deezer preview found:', preview);
-							
-							// Libérer le verrou de chargement
-							audioLoading = false;
-							
-							// Retourner l'URL HTTPS de la preview
-							return `/api/audio-proxy?url=${encodeURIComponent(preview)}`;
-						}
+						// Libérer le verrou de chargement
+						audioLoading = false;
+						
+						return data.preview_url;
 					}
 					
-					// Aucune preview trouvée dans les résultats Deezer
-					console.log('⚠️ No Deezer results for:', artistName);
+					// 404 : aucune preview pour cet artiste
+					console.log('⚠️ No preview for:', artist.name, `(${res.status})`);
 				} catch (err) {
-					// Erreur lors de la requête Deezer (réseau, timeout, etc.)
-					console.error('❌ Deezer API error:', err);
+					// Erreur réseau lors de l'appel au serveur
+					console.error('❌ Preview API error:', err);
 				}
 				
-				// ============================================================
-				// ÉCHEC COMPLET : AUCUNE PREVIEW TROUVÉE
-				// ============================================================
-				// Si ni iTunes ni Deezer n'ont retourné de preview, on utilisera
-				// le MP3 fallback par défaut (3s sample) défini plus haut.
-				// ============================================================
-				
 				// Logger l'échec pour le monitoring
-				console.warn('❌ No preview found for:', artistName);
+				console.warn('❌ No preview found for:', artist.name);
 				
 				// Libérer le verrou de chargement
 				audioLoading = false;
@@ -714,7 +575,7 @@ document.addEventListener('DOMContentLoaded', function () {
 			
 			// Lancer la recherche de preview pour cet artiste (asynchrone)
 			// .then() s'exécutera quand la Promise fetchMusicPreview() se résout
-			fetchMusicPreview(a.name || '').then(previewUrl => {
+			fetchMusicPreview(a).then(previewUrl => {
 				// Si une preview a été trouvée (iTunes ou Deezer)
 				if (previewUrl) {
 					// Debug : confirmer l'URL récupérée
@@ -827,7 +688,7 @@ document.addEventListener('DOMContentLoaded', function () {
 					console.log('⏳ No audio source, fetching now...');
 					
 					// Relancer une recherche de preview
-					fetchMusicPreview(a.name || '').then(previewUrl => {
+					fetchMusicPreview(a).then(previewUrl => {
 						// Si une preview est trouvée cette fois
 						if (previewUrl) {
 							audio.src = previewUrl;